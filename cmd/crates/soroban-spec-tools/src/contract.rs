@@ -41,14 +41,19 @@ pub enum Error {
 impl Spec {
     pub fn new(bytes: &[u8]) -> Result<Self, Error> {
         let mut env_meta: Option<&[u8]> = None;
-        let mut meta: Option<&[u8]> = None;
+        // Meta can be spread over several sections, e.g. when build metadata is appended
+        let mut meta: Option<Vec<u8>> = None;
         let mut spec: Option<&[u8]> = None;
         for payload in wasmparser::Parser::new(0).parse_all(bytes) {
             let payload = payload?;
             if let wasmparser::Payload::CustomSection(section) = payload {
                 let out = match section.name() {
                     "contractenvmetav0" => &mut env_meta,
-                    "contractmetav0" => &mut meta,
+                    "contractmetav0" => {
+                        meta.get_or_insert_with(Vec::new)
+                            .extend_from_slice(section.data());
+                        continue;
+                    }
                     "contractspecv0" => &mut spec,
                     _ => continue,
                 };
@@ -68,7 +73,7 @@ impl Spec {
 
         let mut meta_base64 = None;
         let meta = if let Some(meta) = meta {
-            meta_base64 = Some(base64.encode(meta));
+            meta_base64 = Some(base64.encode(&meta));
             let cursor = Cursor::new(meta);
             let mut depth_limit_read = Limited::new(cursor, Limits::none());
             ScMetaEntry::read_xdr_iter(&mut depth_limit_read)
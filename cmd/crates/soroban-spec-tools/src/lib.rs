@@ -4,13 +4,13 @@ use std::str::FromStr;
 use itertools::Itertools;
 use serde_json::{json, Value};
 use stellar_xdr::curr::{
-    AccountId, BytesM, ContractExecutable, Error as XdrError, Hash, Int128Parts, Int256Parts,
-    PublicKey, ScAddress, ScBytes, ScContractInstance, ScMap, ScMapEntry, ScNonceKey, ScSpecEntry,
-    ScSpecFunctionV0, ScSpecTypeDef as ScType, ScSpecTypeMap, ScSpecTypeOption, ScSpecTypeResult,
-    ScSpecTypeTuple, ScSpecTypeUdt, ScSpecTypeVec, ScSpecUdtEnumV0, ScSpecUdtErrorEnumCaseV0,
-    ScSpecUdtErrorEnumV0, ScSpecUdtStructV0, ScSpecUdtUnionCaseTupleV0, ScSpecUdtUnionCaseV0,
-    ScSpecUdtUnionCaseVoidV0, ScSpecUdtUnionV0, ScString, ScSymbol, ScVal, ScVec, StringM,
-    UInt128Parts, UInt256Parts, Uint256, VecM,
+    AccountId, BytesM, ContractExecutable, Duration, Error as XdrError, Hash, Int128Parts,
+    Int256Parts, PublicKey, ScAddress, ScBytes, ScContractInstance, ScMap, ScMapEntry, ScNonceKey,
+    ScSpecEntry, ScSpecFunctionV0, ScSpecTypeDef as ScType, ScSpecTypeMap, ScSpecTypeOption,
+    ScSpecTypeResult, ScSpecTypeTuple, ScSpecTypeUdt, ScSpecTypeVec, ScSpecUdtEnumV0,
+    ScSpecUdtErrorEnumCaseV0, ScSpecUdtErrorEnumV0, ScSpecUdtStructV0, ScSpecUdtUnionCaseTupleV0,
+    ScSpecUdtUnionCaseV0, ScSpecUdtUnionCaseVoidV0, ScSpecUdtUnionV0, ScString, ScSymbol, ScVal,
+    ScVec, StringM, TimePoint, UInt128Parts, UInt256Parts, Uint256, VecM,
 };
 
 pub mod contract;
@@ -22,8 +22,17 @@ pub enum Error {
     Unknown,
     #[error("Invalid pair {0:#?} {1:#?}")]
     InvalidPair(ScVal, ScType),
-    #[error("value is not parseable to {0:#?}")]
+    #[error(
+        "value is not parseable to {}",
+        .0.as_ref().map_or_else(|| "the expected type".to_string(), type_name)
+    )]
     InvalidValue(Option<ScType>),
+    #[error("{value:?} is not a valid {}, expected {hint}", type_name(.type_))]
+    InvalidLiteral {
+        value: String,
+        type_: ScType,
+        hint: &'static str,
+    },
     #[error("Unknown case {0} for {1}")]
     EnumCase(String, String),
     #[error("Enum {0} missing value for type {1}")]
@@ -68,6 +77,52 @@ pub enum Error {
 #[derive(Default, Clone)]
 pub struct Spec(pub Option<Vec<ScSpecEntry>>);
 
+/// Rust-like name of a spec type, e.g. `Option<Vec<Address>>`.
+pub fn type_name(type_: &ScType) -> String {
+    match type_ {
+        ScType::Val => "Val".to_string(),
+        ScType::Bool => "bool".to_string(),
+        ScType::Void => "()".to_string(),
+        ScType::Error => "Error".to_string(),
+        ScType::U32 => "u32".to_string(),
+        ScType::I32 => "i32".to_string(),
+        ScType::U64 => "u64".to_string(),
+        ScType::I64 => "i64".to_string(),
+        ScType::Timepoint => "Timepoint".to_string(),
+        ScType::Duration => "Duration".to_string(),
+        ScType::U128 => "u128".to_string(),
+        ScType::I128 => "i128".to_string(),
+        ScType::U256 => "U256".to_string(),
+        ScType::I256 => "I256".to_string(),
+        ScType::Bytes => "Bytes".to_string(),
+        ScType::String => "String".to_string(),
+        ScType::Symbol => "Symbol".to_string(),
+        ScType::Address => "Address".to_string(),
+        ScType::Option(o) => format!("Option<{}>", type_name(&o.value_type)),
+        ScType::Result(r) => format!(
+            "Result<{}, {}>",
+            type_name(&r.ok_type),
+            type_name(&r.error_type)
+        ),
+        ScType::Vec(v) => format!("Vec<{}>", type_name(&v.element_type)),
+        ScType::Map(m) => format!(
+            "Map<{}, {}>",
+            type_name(&m.key_type),
+            type_name(&m.value_type)
+        ),
+        ScType::Tuple(t) => format!(
+            "({})",
+            t.value_types
+                .iter()
+                .map(type_name)
+                .collect::<Vec<_>>()
+                .join(", ")
+        ),
+        ScType::BytesN(b) => format!("BytesN<{}>", b.n),
+        ScType::Udt(u) => u.name.to_utf8_string_lossy(),
+    }
+}
+
 impl TryInto<Spec> for &[u8] {
     type Error = soroban_spec::read::FromWasmError;
 
@@ -246,6 +301,13 @@ impl Spec {
                     | ScType::I256
                     | ScType::U128
                     | ScType::I128
+                    | ScType::U64
+                    | ScType::I64
+                    | ScType::U32
+                    | ScType::I32
+                    | ScType::Timepoint
+                    | ScType::Duration
+                    | ScType::Bool
                     | ScType::Address => Ok(Value::String(s.to_owned())),
                     ScType::Udt(ScSpecTypeUdt { name })
                         if matches!(
@@ -283,6 +345,8 @@ impl Spec {
                 | ScType::I64
                 | ScType::U32
                 | ScType::U64
+                | ScType::Timepoint
+                | ScType::Duration
                 | ScType::String
                 | ScType::Symbol
                 | ScType::Address
@@ -761,12 +825,34 @@ pub fn from_json_primitives(v: &Value, t: &ScType) -> Result<ScVal, Error> {
         // Boolean parsing
         (ScType::Bool, Value::Bool(true)) => ScVal::Bool(true),
         (ScType::Bool, Value::Bool(false)) => ScVal::Bool(false),
+        (ScType::Bool, Value::String(s)) if s.eq_ignore_ascii_case("true") => ScVal::Bool(true),
+        (ScType::Bool, Value::String(s)) if s.eq_ignore_ascii_case("false") => ScVal::Bool(false),
+        (ScType::Bool, Value::String(s)) => return Err(invalid_literal(s, t, BOOL_HINT)),
+
+        // Large integers given as JSON numbers, e.g. in an arguments file
+        (ScType::U128 | ScType::I128 | ScType::U256 | ScType::I256, Value::Number(n)) => {
+            from_json_primitives(&Value::String(n.to_string()), t)?
+        }
+        // Integers given as strings, which may use `_` separators and a `0x` prefix
+        (ScType::I32 | ScType::I64, Value::String(s)) => {
+            let n = parse_i128(s).ok_or_else(|| invalid_literal(s, t, INT_HINT))?;
+            let n = i64::try_from(n).map_err(|_| invalid_literal(s, t, INT_HINT))?;
+            from_json_primitives(&Value::Number(n.into()), t)?
+        }
+        (ScType::U32 | ScType::Duration, Value::String(s)) => {
+            let n = parse_u128(s).ok_or_else(|| invalid_literal(s, t, INT_HINT))?;
+            let n = u64::try_from(n).map_err(|_| invalid_literal(s, t, INT_HINT))?;
+            from_json_primitives(&Value::Number(n.into()), t)?
+        }
+        // Timepoints, and u64s which commonly hold them, may also be given as a date or timestamp
+        (ScType::Timepoint | ScType::U64, Value::String(s)) => {
+            let n = parse_timestamp(s).ok_or_else(|| invalid_literal(s, t, TIMESTAMP_HINT))?;
+            from_json_primitives(&Value::Number(n.into()), t)?
+        }
 
         // Number parsing
         (ScType::U128, Value::String(s)) => {
-            let val: u128 = u128::from_str(s)
-                .map(Into::into)
-                .map_err(|_| Error::InvalidValue(Some(t.clone())))?;
+            let val = parse_u128(s).ok_or_else(|| invalid_literal(s, t, INT_HINT))?;
             let bytes = val.to_be_bytes();
             let (hi, lo) = bytes.split_at(8);
             ScVal::U128(UInt128Parts {
@@ -776,9 +862,7 @@ pub fn from_json_primitives(v: &Value, t: &ScType) -> Result<ScVal, Error> {
         }
 
         (ScType::I128, Value::String(s)) => {
-            let val: i128 = i128::from_str(s)
-                .map(Into::into)
-                .map_err(|_| Error::InvalidValue(Some(t.clone())))?;
+            let val = parse_i128(s).ok_or_else(|| invalid_literal(s, t, INT_HINT))?;
             let bytes = val.to_be_bytes();
             let (hi, lo) = bytes.split_at(8);
             ScVal::I128(Int128Parts {
@@ -789,7 +873,9 @@ pub fn from_json_primitives(v: &Value, t: &ScType) -> Result<ScVal, Error> {
 
         // Number parsing
         (ScType::U256, Value::String(s)) => {
-            let (hi, lo) = ethnum::U256::from_str_prefixed(s)?.into_words();
+            let (hi, lo) = ethnum::U256::from_str_prefixed(&s.replace('_', ""))
+                .map_err(|_| invalid_literal(s, t, INT_HINT))?
+                .into_words();
             let hi_bytes = hi.to_be_bytes();
             let (hi_hi, hi_lo) = hi_bytes.split_at(8);
             let lo_bytes = lo.to_be_bytes();
@@ -802,7 +888,9 @@ pub fn from_json_primitives(v: &Value, t: &ScType) -> Result<ScVal, Error> {
             })
         }
         (ScType::I256, Value::String(s)) => {
-            let (hi, lo) = ethnum::I256::from_str_prefixed(s)?.into_words();
+            let (hi, lo) = ethnum::I256::from_str_prefixed(&s.replace('_', ""))
+                .map_err(|_| invalid_literal(s, t, INT_HINT))?
+                .into_words();
             let hi_bytes = hi.to_be_bytes();
             let (hi_hi, hi_lo) = hi_bytes.split_at(8);
             let lo_bytes = lo.to_be_bytes();
@@ -831,10 +919,18 @@ pub fn from_json_primitives(v: &Value, t: &ScType) -> Result<ScVal, Error> {
             n.as_i64()
                 .ok_or_else(|| Error::InvalidValue(Some(t.clone())))?,
         ),
-        (ScType::U64 | ScType::Timepoint | ScType::Duration, Value::Number(n)) => ScVal::U64(
+        (ScType::U64, Value::Number(n)) => ScVal::U64(
             n.as_u64()
                 .ok_or_else(|| Error::InvalidValue(Some(t.clone())))?,
         ),
+        (ScType::Timepoint, Value::Number(n)) => ScVal::Timepoint(TimePoint(
+            n.as_u64()
+                .ok_or_else(|| Error::InvalidValue(Some(t.clone())))?,
+        )),
+        (ScType::Duration, Value::Number(n)) => ScVal::Duration(Duration(
+            n.as_u64()
+                .ok_or_else(|| Error::InvalidValue(Some(t.clone())))?,
+        )),
 
         // Symbol parsing
         (ScType::Symbol, Value::String(s)) => ScVal::Symbol(ScSymbol(
@@ -858,20 +954,27 @@ pub fn from_json_primitives(v: &Value, t: &ScType) -> Result<ScVal, Error> {
                     return Ok(key);
                 }
             }
-            // Bytes are not an address, just parse as a hex string
-            utils::padded_hex_from_str(s, bytes.n as usize)
-                .map_err(|_| Error::InvalidValue(Some(t.clone())))?
-                .try_into()
-                .map_err(|_| Error::InvalidValue(Some(t.clone())))?
+            // Bytes are not an address, parse as base64 if marked as such, otherwise as hex
+            let n = bytes.n as usize;
+            match s.strip_prefix(BASE64_PREFIX) {
+                Some(base64) => base64_bytes(base64).filter(|b| b.len() == n),
+                None => utils::padded_hex_from_str(s.strip_prefix("0x").unwrap_or(s), n).ok(),
+            }
+            .ok_or_else(|| invalid_literal(s, t, BYTES_HINT))?
+            .try_into()
+            .map_err(|_| Error::InvalidValue(Some(t.clone())))?
         })),
         (ScType::Bytes, Value::Number(n)) => {
             from_json_primitives(&Value::String(format!("{n}")), &ScType::Bytes)?
         }
         (ScType::Bytes, Value::String(s)) => ScVal::Bytes(
-            hex::decode(s)
-                .map_err(|_| Error::InvalidValue(Some(t.clone())))?
-                .try_into()
-                .map_err(|_| Error::InvalidValue(Some(t.clone())))?,
+            match s.strip_prefix(BASE64_PREFIX) {
+                Some(base64) => base64_bytes(base64),
+                None => hex::decode(s.strip_prefix("0x").unwrap_or(s)).ok(),
+            }
+            .ok_or_else(|| invalid_literal(s, t, BYTES_HINT))?
+            .try_into()
+            .map_err(|_| Error::InvalidValue(Some(t.clone())))?,
         ),
         (ScType::Bytes | ScType::BytesN(_), Value::Array(raw)) => {
             let b: Result<Vec<u8>, Error> = raw
@@ -897,6 +1000,56 @@ pub fn from_json_primitives(v: &Value, t: &ScType) -> Result<ScVal, Error> {
     Ok(val)
 }
 
+const INT_HINT: &str = "a decimal or `0x` prefixed hex integer in range, e.g. 1_000_000";
+const BYTES_HINT: &str =
+    "hex, `0x` prefixed hex, or `base64:` prefixed base64 bytes of the right length";
+/// Marks bytes written as base64, since many base64 strings are valid hex too.
+const BASE64_PREFIX: &str = "base64:";
+const BOOL_HINT: &str = "true or false";
+const TIMESTAMP_HINT: &str = "an integer such as seconds since the Unix epoch, a date such as \
+    2024-01-31, or a timestamp such as 2024-01-31T12:00:00Z";
+
+fn invalid_literal(s: &str, t: &ScType, hint: &'static str) -> Error {
+    Error::InvalidLiteral {
+        value: s.to_string(),
+        type_: t.clone(),
+        hint,
+    }
+}
+
+/// Parse an unsigned integer that may use `_` separators and a `0x` prefix.
+fn parse_u128(s: &str) -> Option<u128> {
+    let s = s.replace('_', "");
+    match s.strip_prefix("0x") {
+        Some(hex) => u128::from_str_radix(hex, 16).ok(),
+        None => u128::from_str(&s).ok(),
+    }
+}
+
+/// Parse a signed integer that may use `_` separators and a `0x` prefix after the sign.
+fn parse_i128(s: &str) -> Option<i128> {
+    match s.strip_prefix('-') {
+        Some(magnitude) => 0i128.checked_sub_unsigned(parse_u128(magnitude)?),
+        None => i128::try_from(parse_u128(s)?).ok(),
+    }
+}
+
+/// Seconds since the Unix epoch of a number, a date (midnight UTC) or an RFC 3339 timestamp.
+fn parse_timestamp(s: &str) -> Option<u64> {
+    if let Some(n) = parse_u128(s) {
+        return u64::try_from(n).ok();
+    }
+    let timestamp = chrono::DateTime::parse_from_rfc3339(s)
+        .or_else(|_| chrono::DateTime::parse_from_rfc3339(&format!("{s}T00:00:00Z")))
+        .ok()?;
+    u64::try_from(timestamp.timestamp()).ok()
+}
+
+fn base64_bytes(s: &str) -> Option<Vec<u8>> {
+    use base64::{engine::general_purpose::STANDARD, Engine as _};
+    STANDARD.decode(s).ok()
+}
+
 /// # Errors
 ///
 /// Might return an error
@@ -1410,6 +1563,87 @@ mod tests {
         );
     }
 
+    #[test]
+    fn from_string_integer_literals() {
+        assert_eq!(
+            from_string_primitive("1_000", &ScType::I128).unwrap(),
+            ScVal::I128(Int128Parts { hi: 0, lo: 1000 })
+        );
+        assert_eq!(
+            from_string_primitive("0xff", &ScType::U32).unwrap(),
+            ScVal::U32(255)
+        );
+        assert_eq!(
+            from_string_primitive("-0x10", &ScType::I64).unwrap(),
+            ScVal::I64(-16)
+        );
+        assert_eq!(
+            from_json_primitives(&Value::Number(7.into()), &ScType::U128).unwrap(),
+            ScVal::U128(UInt128Parts { hi: 0, lo: 7 })
+        );
+        let err = from_string_primitive("abc", &ScType::U32).unwrap_err();
+        assert!(err
+            .to_string()
+            .starts_with("\"abc\" is not a valid u32, expected"));
+    }
+
+    #[test]
+    fn from_string_byte_and_time_literals() {
+        let beefface = ScVal::Bytes(ScBytes(vec![0xbe, 0xef, 0xfa, 0xce].try_into().unwrap()));
+        assert_eq!(
+            from_string_primitive("0xbeefface", &ScType::Bytes).unwrap(),
+            beefface
+        );
+        assert_eq!(
+            from_string_primitive(
+                "base64:vu/6zg==",
+                &ScType::BytesN(ScSpecTypeBytesN { n: 4 })
+            )
+            .unwrap(),
+            beefface
+        );
+        assert_eq!(
+            from_string_primitive("True", &ScType::Bool).unwrap(),
+            ScVal::Bool(true)
+        );
+        for timestamp in ["2024-01-31", "2024-01-31T00:00:00Z", "1706659200"] {
+            assert_eq!(
+                from_string_primitive(timestamp, &ScType::Timepoint).unwrap(),
+                ScVal::Timepoint(TimePoint(1_706_659_200))
+            );
+        }
+        assert_eq!(
+            from_string_primitive("3_600", &ScType::Duration).unwrap(),
+            ScVal::Duration(Duration(3600))
+        );
+    }
+
+    #[test]
+    fn bytes_are_hex_unless_marked_base64() {
+        // "deadbeef" is valid hex and valid base64, so it is read as hex unless prefixed
+        assert_eq!(
+            from_string_primitive("deadbeef", &ScType::Bytes).unwrap(),
+            ScVal::Bytes(ScBytes(vec![0xde, 0xad, 0xbe, 0xef].try_into().unwrap()))
+        );
+        assert_eq!(
+            from_string_primitive("base64:deadbeef", &ScType::Bytes).unwrap(),
+            ScVal::Bytes(ScBytes(
+                vec![0x75, 0xe6, 0x9d, 0x6d, 0xe7, 0x9f].try_into().unwrap()
+            ))
+        );
+        assert!(from_string_primitive("vu/6zg==", &ScType::Bytes).is_err());
+    }
+
+    #[test]
+    fn nested_type_names() {
+        let t = ScType::Option(Box::new(ScSpecTypeOption {
+            value_type: Box::new(ScType::Vec(Box::new(ScSpecTypeVec {
+                element_type: Box::new(ScType::Address),
+            }))),
+        }));
+        assert_eq!(type_name(&t), "Option<Vec<Address>>");
+    }
+
     #[test]
     fn test_sc_address_from_json_strkey() {
         // All zero contract address
@@ -225,6 +225,9 @@ impl TestEnv {
                 rpc_url: Some(self.rpc_url.clone()),
                 network_passphrase: Some(LOCAL_NETWORK_PASSPHRASE.to_string()),
                 network: None,
+                strict_version: false,
+                rpc_retries: None,
+                rpc_timeout: None,
             },
             source_account: account.to_string(),
             locator: config::locator::Args {
@@ -232,6 +235,7 @@ impl TestEnv {
                 config_dir,
             },
             hd_path: None,
+            sign_with_cmd: None,
         }
     }
 
@@ -249,8 +253,10 @@ impl TestEnv {
                 quiet: false,
                 verbose: false,
                 very_verbose: false,
+                no_color: false,
                 list: false,
                 no_cache: false,
+                progress: global::Progress::Human,
             }),
             Some(&config),
         )
@@ -3,7 +3,10 @@ use soroban_test::{AssertExt, TestEnv};
 use std::{fs, path::Path};
 
 use crate::util::{add_key, add_test_id, SecretKind, DEFAULT_SEED_PHRASE};
-use soroban_cli::commands::network::{self, LOCAL_NETWORK_PASSPHRASE};
+use soroban_cli::commands::{
+    config::locator,
+    network::{self, LOCAL_NETWORK_PASSPHRASE},
+};
 
 fn ls(sandbox: &TestEnv) -> Vec<String> {
     sandbox
@@ -257,3 +260,105 @@ fn use_env() {
         .success()
         .stdout("SDIY6AQQ75WMD4W46EYB7O6UYMHOCGQHLAQGQTKHDX4J2DYQCHVCQYFD\n");
 }
+
+#[test]
+fn show_public_key() {
+    let sandbox = TestEnv::default();
+    add_test_id(sandbox.dir());
+    let address = sandbox
+        .new_assert_cmd("keys")
+        .arg("address")
+        .arg("test_id")
+        .assert()
+        .success()
+        .stdout_as_str();
+    sandbox
+        .new_assert_cmd("keys")
+        .arg("show")
+        .arg("test_id")
+        .arg("--public")
+        .assert()
+        .success()
+        .stdout(format!("{address}\n"));
+    let hex = sandbox
+        .new_assert_cmd("keys")
+        .arg("show")
+        .arg("test_id")
+        .arg("--public")
+        .arg("--format=hex")
+        .assert()
+        .success()
+        .stdout_as_str();
+    let bytes = stellar_strkey::ed25519::PublicKey::from_string(&address)
+        .unwrap()
+        .0;
+    assert_eq!(
+        hex,
+        bytes.iter().map(|b| format!("{b:02x}")).collect::<String>()
+    );
+}
+
+const ALICE: &str = "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF";
+const TOKEN: &str = "CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABSC4";
+
+fn add_address(sandbox: &TestEnv, label: &str, address: &str) {
+    sandbox
+        .new_assert_cmd("address")
+        .args(["add", label, address])
+        .assert()
+        .success();
+}
+
+#[test]
+fn add_and_remove_address_label() {
+    let sandbox = TestEnv::default();
+    add_address(&sandbox, "alice", ALICE);
+    sandbox
+        .new_assert_cmd("address")
+        .arg("ls")
+        .assert()
+        .success()
+        .stdout(format!("alice: {ALICE}\n"));
+    sandbox
+        .new_assert_cmd("address")
+        .args(["rm", "alice"])
+        .assert()
+        .success();
+    sandbox
+        .new_assert_cmd("address")
+        .arg("ls")
+        .assert()
+        .success()
+        .stdout("");
+}
+
+#[test]
+fn address_label_must_be_an_address() {
+    let sandbox = TestEnv::default();
+    sandbox
+        .new_assert_cmd("address")
+        .args(["add", "alice", "alice"])
+        .assert()
+        .failure();
+}
+
+#[test]
+fn only_contract_labels_resolve_to_contracts() {
+    let sandbox = TestEnv::default();
+    add_address(&sandbox, "alice", ALICE);
+    add_address(&sandbox, "token", TOKEN);
+    let locator = locator::Args {
+        global: false,
+        config_dir: Some(sandbox.dir().to_path_buf()),
+    };
+    assert_eq!(
+        locator
+            .resolve_contract_id("token", LOCAL_NETWORK_PASSPHRASE)
+            .unwrap()
+            .to_string(),
+        TOKEN
+    );
+    assert!(locator
+        .resolve_contract_id("alice", LOCAL_NETWORK_PASSPHRASE)
+        .is_err());
+}
@@ -2,6 +2,7 @@ use predicates::boolean::PredicateBooleanExt;
 use soroban_cli::commands::{
     config::{locator, secret},
     contract::{self, fetch},
+    global,
     txn_result::TxnResult,
 };
 use soroban_rpc::GetLatestLedgerResponse;
@@ -157,7 +158,7 @@ fn invoke_hello_world(sandbox: &TestEnv, id: &str) {
 
 fn hello_world_cmd(id: &str, arg: &str) -> contract::invoke::Cmd {
     contract::invoke::Cmd {
-        contract_id: id.to_string(),
+        contract_id: Some(id.to_string()),
         slop: vec!["hello".into(), format!("--world={arg}").into()],
         ..Default::default()
     }
@@ -173,6 +174,7 @@ fn invoke_auth(sandbox: &TestEnv, id: &str, addr: &str) {
     sandbox
         .new_assert_cmd("contract")
         .arg("invoke")
+        .arg("--yes")
         .arg("--id")
         .arg(id)
         .arg("--")
@@ -187,6 +189,7 @@ fn invoke_auth(sandbox: &TestEnv, id: &str, addr: &str) {
     sandbox
         .new_assert_cmd("contract")
         .arg("invoke")
+        .arg("--yes")
         .arg("--id")
         .arg(id)
         .arg("--")
@@ -204,6 +207,7 @@ fn invoke_auth_with_identity(sandbox: &TestEnv, id: &str, key: &str, addr: &str)
         .arg("invoke")
         .arg("--source")
         .arg(key)
+        .arg("--yes")
         .arg("--id")
         .arg(id)
         .arg("--")
@@ -220,6 +224,7 @@ async fn invoke_auth_with_different_test_account_fail(sandbox: &TestEnv, id: &st
     let res = sandbox
         .invoke_with_test(&[
             "--hd-path=0",
+            "--yes",
             "--id",
             id,
             "--",
@@ -256,7 +261,7 @@ async fn contract_data_read() {
     let sandbox = &TestEnv::new();
     let id = &deploy_hello(sandbox).await;
     let res = sandbox
-        .invoke_with_test(&["--id", id, "--", "inc"])
+        .invoke_with_test(&["--yes", "--id", id, "--", "inc"])
         .await
         .unwrap();
     assert_eq!(res.trim(), "1");
@@ -277,6 +282,7 @@ async fn contract_data_read() {
     sandbox
         .new_assert_cmd("contract")
         .arg("invoke")
+        .arg("--yes")
         .arg("--id")
         .arg(id)
         .arg("--")
@@ -297,6 +303,31 @@ async fn contract_data_read() {
         .stdout(predicates::str::starts_with("COUNTER,2"));
 }
 
+#[tokio::test]
+async fn writes_need_yes_without_terminal() {
+    let sandbox = &TestEnv::new();
+    let id = &deploy_hello(sandbox).await;
+    sandbox
+        .new_assert_cmd("contract")
+        .arg("invoke")
+        .arg("--id")
+        .arg(id)
+        .arg("--")
+        .arg("inc")
+        .assert()
+        .failure()
+        .stderr(predicates::str::contains("there is no terminal to confirm"));
+    sandbox
+        .new_assert_cmd("contract")
+        .arg("deploy")
+        .arg("--wasm")
+        .arg(HELLO_WORLD.path())
+        .arg("--ignore-checks")
+        .assert()
+        .failure()
+        .stderr(predicates::str::contains("without a terminal, pass --yes"));
+}
+
 #[tokio::test]
 #[ignore]
 async fn half_max_instructions() {
@@ -312,6 +343,7 @@ async fn half_max_instructions() {
         .arg("--wasm")
         .arg(wasm.path())
         .arg("--ignore-checks")
+        .arg("--yes")
         .assert()
         .stderr("")
         .stdout_as_str();
@@ -356,7 +388,7 @@ async fn fetch(sandbox: &TestEnv, id: &str) {
         "--out-file",
         f.to_str().unwrap(),
     ]);
-    cmd.run().await.unwrap();
+    cmd.run(&global::Args::default()).await.unwrap();
     assert!(f.exists());
 }
 
@@ -68,6 +68,7 @@ pub async fn deploy_contract(
         "--salt",
         TEST_SALT,
         "--ignore-checks",
+        "--yes",
         deploy.to_string().as_str(),
     ]);
     let res = sandbox.run_cmd_with(cmd, "test").await.unwrap();
@@ -95,6 +96,7 @@ pub async fn extend(sandbox: &TestEnv, id: &str, value: Option<&str>) {
         "persistent",
         "--ledgers-to-extend",
         "100000",
+        "--yes",
     ];
     if let Some(value) = value {
         args.push("--key");
@@ -18,6 +18,7 @@ async fn burn() {
         .arg("asset")
         .arg("deploy")
         .arg("--source=test")
+        .arg("--yes")
         .arg("--asset")
         .arg(&asset)
         .assert()
@@ -72,6 +73,7 @@ async fn burn() {
         .env("RUST_LOGS", "trace")
         .args([
             "--source=test",
+            "--yes",
             "--id",
             &id,
             "--",
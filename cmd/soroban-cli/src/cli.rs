@@ -47,6 +47,11 @@ pub async fn main() {
             .add_directive("hyper=off".parse().unwrap())
             .add_directive(format!("stellar_cli={level}").parse().unwrap())
             .add_directive(format!("soroban_cli={level}").parse().unwrap());
+        if level == tracing::Level::DEBUG || level == tracing::Level::TRACE {
+            // Surface the underlying RPC requests and responses when verbose
+            e_filter =
+                e_filter.add_directive(format!("stellar_rpc_client={level}").parse().unwrap());
+        }
 
         for filter in &root.global_args.filter_logs {
             e_filter = e_filter.add_directive(
@@ -0,0 +1,29 @@
+use super::super::config::locator;
+use clap::command;
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Config(#[from] locator::Error),
+}
+
+#[derive(Debug, clap::Parser, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// Label to show next to the address
+    pub label: String,
+
+    /// Account (G...) or contract (C...) address
+    pub address: String,
+
+    #[command(flatten)]
+    pub config_locator: locator::Args,
+}
+
+impl Cmd {
+    pub fn run(&self) -> Result<(), Error> {
+        Ok(self
+            .config_locator
+            .write_address(&self.label, &self.address)?)
+    }
+}
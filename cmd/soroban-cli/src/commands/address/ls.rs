@@ -0,0 +1,48 @@
+use clap::command;
+
+use super::locator;
+use crate::commands::config::locator::Location;
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Config(#[from] locator::Error),
+}
+
+#[derive(Debug, clap::Parser, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    #[command(flatten)]
+    pub config_locator: locator::Args,
+    /// Also show where each label is stored
+    #[arg(long, short = 'l')]
+    pub long: bool,
+}
+
+impl Cmd {
+    pub fn run(&self) -> Result<(), Error> {
+        let res = self.ls()?.join("\n");
+        if !res.is_empty() {
+            println!("{res}");
+        }
+        Ok(())
+    }
+
+    pub fn ls(&self) -> Result<Vec<String>, Error> {
+        Ok(self
+            .config_locator
+            .list_addresses_long()?
+            .iter()
+            .filter(|(_, _, location)| {
+                !self.config_locator.global || matches!(location, Location::Global(_))
+            })
+            .map(|(label, address, location)| {
+                if self.long {
+                    format!("{label}: {address} ({location})")
+                } else {
+                    format!("{label}: {address}")
+                }
+            })
+            .collect())
+    }
+}
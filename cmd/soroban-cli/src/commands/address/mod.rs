@@ -0,0 +1,105 @@
+use std::collections::HashMap;
+
+use clap::Parser;
+use regex::Regex;
+
+use super::config::locator;
+
+pub mod add;
+pub mod ls;
+pub mod rm;
+
+#[derive(Debug, Parser)]
+pub enum Cmd {
+    /// Add a label for an account (G...) or contract (C...) address
+    Add(add::Cmd),
+    /// Remove an address label
+    Rm(rm::Cmd),
+    /// List labelled addresses
+    Ls(ls::Cmd),
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Add(#[from] add::Error),
+
+    #[error(transparent)]
+    Rm(#[from] rm::Error),
+
+    #[error(transparent)]
+    Ls(#[from] ls::Error),
+
+    #[error(transparent)]
+    Config(#[from] locator::Error),
+}
+
+impl Cmd {
+    pub fn run(&self) -> Result<(), Error> {
+        match self {
+            Cmd::Add(cmd) => cmd.run()?,
+            Cmd::Rm(cmd) => cmd.run()?,
+            Cmd::Ls(cmd) => cmd.run()?,
+        };
+        Ok(())
+    }
+}
+
+/// Text with the label of every account and contract address in it written next to the address,
+/// e.g. `"GABC..." (alice)`. `labels` maps addresses to their labels.
+pub fn with_labels(text: &str, labels: &HashMap<String, String>) -> String {
+    if labels.is_empty() {
+        return text.to_string();
+    }
+    let addresses = Regex::new(r"\b[GC][A-Z2-7]{55}\b").unwrap();
+    let mut labelled = String::with_capacity(text.len());
+    let mut rest = 0;
+    for found in addresses.find_iter(text) {
+        let Some(label) = labels.get(found.as_str()) else {
+            continue;
+        };
+        // Keep quoted addresses intact and put the label after the closing quote
+        let end = if text[found.end()..].starts_with('"') {
+            found.end() + 1
+        } else {
+            found.end()
+        };
+        labelled.push_str(&text[rest..end]);
+        labelled.push_str(&format!(" ({label})"));
+        rest = end;
+    }
+    labelled.push_str(&text[rest..]);
+    labelled
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    const ALICE: &str = "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF";
+    const TOKEN: &str = "CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABSC4";
+    const BOB: &str = "GBZXN7PIRZGNMHGA7MUUUF4GWPY5AYPV6LY4UV2GL6VJGIQRXFDNMADI";
+
+    #[test]
+    fn labels_known_addresses() {
+        let labels = HashMap::from([
+            (ALICE.to_string(), "alice".to_string()),
+            (TOKEN.to_string(), "token".to_string()),
+        ]);
+        assert_eq!(
+            with_labels(&format!(r#"["{ALICE}","{BOB}"]"#), &labels),
+            format!(r#"["{ALICE}" (alice),"{BOB}"]"#)
+        );
+        assert_eq!(
+            with_labels(&format!("Contract: {TOKEN}"), &labels),
+            format!("Contract: {TOKEN} (token)")
+        );
+    }
+
+    #[test]
+    fn ignores_addresses_inside_other_text() {
+        let labels = HashMap::from([(ALICE.to_string(), "alice".to_string())]);
+        let text = format!("X{ALICE}");
+        assert_eq!(with_labels(&text, &labels), text);
+    }
+}
@@ -0,0 +1,24 @@
+use super::locator;
+use clap::command;
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Locator(#[from] locator::Error),
+}
+
+#[derive(Debug, clap::Parser, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// Label to remove
+    pub label: String,
+
+    #[command(flatten)]
+    pub config: locator::Args,
+}
+
+impl Cmd {
+    pub fn run(&self) -> Result<(), Error> {
+        Ok(self.config.remove_address(&self.label)?)
+    }
+}
@@ -6,3 +6,9 @@ use serde::{Deserialize, Serialize};
 pub struct Data {
     pub ids: HashMap<String, String>,
 }
+
+/// An address book entry, stored under the label it is known by.
+#[derive(Serialize, Deserialize)]
+pub struct Address {
+    pub address: String,
+}
@@ -1,6 +1,7 @@
 use clap::arg;
 use serde::de::DeserializeOwned;
 use std::{
+    collections::HashMap,
     ffi::OsStr,
     fmt::Display,
     fs::{self, create_dir_all, OpenOptions},
@@ -11,7 +12,7 @@ use std::{
 };
 use stellar_strkey::{Contract, DecodeError};
 
-use crate::{utils::find_config_dir, Pwd};
+use crate::{signer, utils::find_config_dir, Pwd};
 
 use super::{alias, network::Network, secret::Secret};
 
@@ -65,6 +66,8 @@ pub enum Error {
     CannotAccessConfigDir,
     #[error("cannot parse contract ID {0}: {1}")]
     CannotParseContractId(String, DecodeError),
+    #[error("{0} is not a valid account (G...) or contract (C...) address")]
+    InvalidAddress(String),
 }
 
 #[derive(Debug, clap::Args, Default, Clone)]
@@ -201,6 +204,10 @@ impl Args {
         KeyType::Identity.read_with_global(name, &self.local_config()?)
     }
 
+    pub fn read_signer(&self, name: &str) -> Result<signer::Plugin, Error> {
+        KeyType::Signer.read_with_global(name, &self.local_config()?)
+    }
+
     pub fn read_network(&self, name: &str) -> Result<Network, Error> {
         let res = KeyType::Network.read_with_global(name, &self.local_config()?);
         if let Err(Error::ConfigMissing(_, _)) = &res {
@@ -221,6 +228,51 @@ impl Args {
         KeyType::Network.remove(name, &self.config_dir()?)
     }
 
+    pub fn write_address(&self, label: &str, address: &str) -> Result<(), Error> {
+        if stellar_strkey::ed25519::PublicKey::from_string(address).is_err()
+            && Contract::from_string(address).is_err()
+        {
+            return Err(Error::InvalidAddress(address.to_string()));
+        }
+        let entry = alias::Address {
+            address: address.to_string(),
+        };
+        KeyType::Address.write(label, &entry, &self.config_dir()?)
+    }
+
+    pub fn read_address(&self, label: &str) -> Result<String, Error> {
+        KeyType::Address
+            .read_with_global::<alias::Address>(label, &self.local_config()?)
+            .map(|entry| entry.address)
+    }
+
+    pub fn remove_address(&self, label: &str) -> Result<(), Error> {
+        KeyType::Address.remove(label, &self.config_dir()?)
+    }
+
+    /// Labels and addresses in the address book, with local entries listed before global ones.
+    pub fn list_addresses_long(&self) -> Result<Vec<(String, String, Location)>, Error> {
+        Ok(KeyType::Address
+            .list_paths(&self.local_and_global()?)
+            .into_iter()
+            .flatten()
+            .filter_map(|(label, location)| {
+                let entry = KeyType::read_from_path::<alias::Address>(location.as_ref()).ok()?;
+                Some((label, entry.address, location))
+            })
+            .collect())
+    }
+
+    /// Map of address to the label it is known by, for rendering output. Local labels take
+    /// precedence over global ones.
+    pub fn address_labels(&self) -> Result<HashMap<String, String>, Error> {
+        let mut labels = HashMap::new();
+        for (label, address, _) in self.list_addresses_long()? {
+            labels.entry(address).or_insert(label);
+        }
+        Ok(labels)
+    }
+
     fn load_contract_from_alias(&self, alias: &str) -> Result<Option<alias::Data>, Error> {
         let path = self.alias_path(alias)?;
 
@@ -287,6 +339,12 @@ impl Args {
     ) -> Result<Contract, Error> {
         let contract_id = self
             .get_contract_id(alias_or_contract_id, network_passphrase)?
+            .or_else(|| {
+                // Only contract addresses in the address book name a contract
+                self.read_address(alias_or_contract_id)
+                    .ok()
+                    .filter(|address| Contract::from_string(address).is_ok())
+            })
             .unwrap_or_else(|| alias_or_contract_id.to_string());
 
         Ok(Contract(
@@ -326,6 +384,8 @@ fn read_dir(dir: &Path) -> Result<Vec<(String, PathBuf)>, Error> {
 pub enum KeyType {
     Identity,
     Network,
+    Address,
+    Signer,
 }
 
 impl Display for KeyType {
@@ -336,6 +396,8 @@ impl Display for KeyType {
             match self {
                 KeyType::Identity => "identity",
                 KeyType::Network => "network",
+                KeyType::Address => "address",
+                KeyType::Signer => "signer",
             }
         )
     }
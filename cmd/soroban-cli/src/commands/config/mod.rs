@@ -1,13 +1,13 @@
 use std::path::PathBuf;
 
 use clap::{arg, command};
+use ed25519_dalek::VerifyingKey;
 use serde::{Deserialize, Serialize};
 
-use soroban_rpc::Client;
-
 use crate::{
+    print::Print,
     signer,
-    xdr::{Transaction, TransactionEnvelope},
+    xdr::{Limits, Transaction, TransactionEnvelope, WriteXdr},
     Pwd,
 };
 
@@ -50,6 +50,10 @@ pub struct Args {
     /// If using a seed phrase, which hierarchical deterministic path to use, e.g. `m/44'/148'/{hd_path}`. Example: `--hd-path 1`. Default: `0`
     pub hd_path: Option<usize>,
 
+    /// Sign with an external program instead of the source account's secret key, so the source account can be a public key. Either a shell command, or the name of a signer declared as `command = "…"` in signer/<name>.toml in the config directory. The program gets the hex transaction hash on stdin, and STELLAR_TX_HASH, STELLAR_TX_ENVELOPE, STELLAR_NETWORK_PASSPHRASE and STELLAR_SIGNER_PUBLIC_KEY in its environment, and must print the hex or base64 signature of the hash
+    #[arg(long, env = "STELLAR_SIGN_WITH_CMD")]
+    pub sign_with_cmd: Option<String>,
+
     #[command(flatten)]
     pub locator: locator::Args,
 }
@@ -64,34 +68,69 @@ impl Args {
         self.sign(tx).await
     }
 
+    /// Public key of the source account. Unlike `key_pair` it also accepts a public key as the
+    /// source account, for transactions signed with `--sign-with-cmd`.
+    pub fn verifying_key(&self) -> Result<VerifyingKey, Error> {
+        match stellar_strkey::ed25519::PublicKey::from_string(&self.source_account) {
+            Ok(key) => Ok(VerifyingKey::from_bytes(&key.0).map_err(signer::Error::from)?),
+            Err(_) => Ok(self.key_pair()?.verifying_key()),
+        }
+    }
+
     #[allow(clippy::unused_async)]
     pub async fn sign(&self, tx: Transaction) -> Result<TransactionEnvelope, Error> {
-        let key = self.key_pair()?;
         let Network {
             network_passphrase, ..
         } = &self.get_network()?;
-        Ok(signer::sign_tx(&key, &tx, network_passphrase)?)
+        let envelope = if let Some(command) = self.sign_command()? {
+            signer::sign_tx_with_cmd(&command, &self.verifying_key()?, &tx, network_passphrase)?
+        } else {
+            signer::sign_tx(&self.key_pair()?, &tx, network_passphrase)?
+        };
+        if let Ok(xdr) = envelope.to_xdr_base64(Limits::none()) {
+            tracing::debug!("signed transaction envelope: {xdr}");
+        }
+        Ok(envelope)
     }
 
     pub async fn sign_soroban_authorizations(
         &self,
         tx: &Transaction,
         signers: &[ed25519_dalek::SigningKey],
+        print: &Print,
     ) -> Result<Option<Transaction>, Error> {
         let network = self.get_network()?;
-        let source_key = self.key_pair()?;
-        let client = Client::new(&network.rpc_url)?;
-        let latest_ledger = client.get_latest_ledger().await?.sequence;
+        // An external signer only signs transactions, so auth entries of the source account
+        // need their own signer
+        let source_key = if self.sign_with_cmd.is_some() {
+            None
+        } else {
+            Some(self.key_pair()?)
+        };
+        let client = network.rpc_client(print).await?;
+        let latest_ledger = client.retry(|| client.get_latest_ledger()).await?.sequence;
         let seq_num = latest_ledger + 60; // ~ 5 min
         Ok(signer::sign_soroban_authorizations(
             tx,
-            &source_key,
+            source_key.as_ref(),
             signers,
             seq_num,
             &network.network_passphrase,
         )?)
     }
 
+    /// Shell command to sign with, resolving the name of a signer declared in the config.
+    fn sign_command(&self) -> Result<Option<String>, Error> {
+        let Some(command) = &self.sign_with_cmd else {
+            return Ok(None);
+        };
+        Ok(Some(match self.locator.read_signer(command) {
+            Ok(plugin) => plugin.command,
+            Err(locator::Error::ConfigMissing(..)) => command.clone(),
+            Err(e) => return Err(e.into()),
+        }))
+    }
+
     pub fn account(&self, account_str: &str) -> Result<Secret, Error> {
         if let Ok(secret) = self.locator.read_identity(account_str) {
             Ok(secret)
@@ -1,3 +1,5 @@
+use crate::commands::global;
+
 pub mod json;
 pub mod rust;
 pub mod typescript;
@@ -27,11 +29,11 @@ pub enum Error {
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
         match &self {
             Cmd::Json(json) => json.run()?,
             Cmd::Rust(rust) => rust.run()?,
-            Cmd::Typescript(ts) => ts.run().await?,
+            Cmd::Typescript(ts) => ts.run(global_args).await?,
         }
         Ok(())
     }
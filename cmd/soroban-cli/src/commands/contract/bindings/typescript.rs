@@ -121,15 +121,14 @@ impl NetworkRunnable for Cmd {
         }
         std::fs::create_dir_all(&self.output_dir)?;
         let p: Project = self.output_dir.clone().try_into()?;
-        let Network {
-            rpc_url,
-            network_passphrase,
-            ..
-        } = self
+        let network = self
             .network
             .get(&self.locator)
             .ok()
             .unwrap_or_else(Network::futurenet);
+        // Bindings can be generated offline, so the first endpoint of the RPC URL is used without
+        // checking which one answers
+        let rpc_url = network.rpc_urls().next().unwrap_or_default();
         let absolute_path = self.output_dir.canonicalize()?;
         let file_name = absolute_path
             .file_name()
@@ -140,8 +139,8 @@ impl NetworkRunnable for Cmd {
         p.init(
             contract_name,
             &self.contract_id,
-            &rpc_url,
-            &network_passphrase,
+            rpc_url,
+            &network.network_passphrase,
             &spec,
         )?;
         std::process::Command::new("npm")
@@ -160,7 +159,7 @@ impl NetworkRunnable for Cmd {
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
-        self.run_against_rpc_server(None, None).await
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        self.run_against_rpc_server(Some(global_args), None).await
     }
 }
@@ -18,7 +18,9 @@ use crate::{
         txn_result::{TxnEnvelopeResult, TxnResult},
         NetworkRunnable,
     },
-    rpc::{Client, Error as SorobanRpcError},
+    print::Print,
+    review,
+    rpc::Error as SorobanRpcError,
     utils::{contract_id_hash_from_asset, parsing::parse_asset},
 };
 
@@ -44,6 +46,8 @@ pub enum Error {
     Data(#[from] data::Error),
     #[error(transparent)]
     Network(#[from] network::Error),
+    #[error(transparent)]
+    Review(#[from] review::Error),
 }
 
 impl From<Infallible> for Error {
@@ -66,8 +70,11 @@ pub struct Cmd {
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
-        let res = self.run_against_rpc_server(None, None).await?.to_envelope();
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let res = self
+            .run_against_rpc_server(Some(global_args), None)
+            .await?
+            .to_envelope();
         match res {
             TxnEnvelopeResult::TxnEnvelope(tx) => println!("{}", tx.to_xdr_base64(Limits::none())?),
             TxnEnvelopeResult::Res(contract) => {
@@ -88,22 +95,22 @@ impl NetworkRunnable for Cmd {
         args: Option<&global::Args>,
         config: Option<&config::Args>,
     ) -> Result<Self::Result, Error> {
+        let print = args.map(Print::new).unwrap_or_default();
         let config = config.unwrap_or(&self.config);
         // Parse asset
         let asset = parse_asset(&self.asset)?;
 
         let network = config.get_network()?;
-        let client = Client::new(&network.rpc_url)?;
+        let client = network.rpc_client(&print).await?;
         client
             .verify_network_passphrase(Some(&network.network_passphrase))
             .await?;
-        let key = config.key_pair()?;
+        let key = config.verifying_key()?;
 
         // Get the account sequence number
-        let public_strkey =
-            stellar_strkey::ed25519::PublicKey(key.verifying_key().to_bytes()).to_string();
+        let public_strkey = stellar_strkey::ed25519::PublicKey(key.to_bytes()).to_string();
         // TODO: use symbols for the method names (both here and in serve)
-        let account_details = client.get_account(&public_strkey).await?;
+        let account_details = client.retry(|| client.get_account(&public_strkey)).await?;
         let sequence: i64 = account_details.seq_num.into();
         let network_passphrase = &network.network_passphrase;
         let contract_id = contract_id_hash_from_asset(&asset, network_passphrase)?;
@@ -118,17 +125,26 @@ impl NetworkRunnable for Cmd {
         if self.fee.build_only {
             return Ok(TxnResult::Txn(tx));
         }
-        let txn = client.simulate_and_assemble_transaction(&tx).await?;
+        let txn = client
+            .retry(|| client.simulate_and_assemble_transaction(&tx))
+            .await?;
         let txn = self.fee.apply_to_assembled_txn(txn).transaction().clone();
         if self.fee.sim_only {
             return Ok(TxnResult::Txn(txn));
         }
+        review::review(
+            &txn,
+            network_passphrase,
+            &config.locator,
+            self.fee.yes,
+            &print,
+        )?;
         let get_txn_resp = client
             .send_transaction_polling(&self.config.sign_with_local_key(txn).await?)
             .await?
             .try_into()?;
         if args.map_or(true, |a| !a.no_cache) {
-            data::write(get_txn_resp, &network.rpc_uri()?)?;
+            data::write(get_txn_resp, &client.uri()?)?;
         }
 
         Ok(TxnResult::Res(stellar_strkey::Contract(contract_id.0)))
@@ -141,7 +157,7 @@ fn build_wrap_token_tx(
     sequence: i64,
     fee: u32,
     _network_passphrase: &str,
-    key: &ed25519_dalek::SigningKey,
+    key: &ed25519_dalek::VerifyingKey,
 ) -> Result<Transaction, Error> {
     let contract = ScAddress::Contract(contract_id.clone());
     let mut read_write = vec![
@@ -180,7 +196,7 @@ fn build_wrap_token_tx(
     };
 
     Ok(Transaction {
-        source_account: MuxedAccount::Ed25519(Uint256(key.verifying_key().to_bytes())),
+        source_account: MuxedAccount::Ed25519(Uint256(key.to_bytes())),
         fee,
         seq_num: SequenceNumber(sequence),
         cond: Preconditions::None,
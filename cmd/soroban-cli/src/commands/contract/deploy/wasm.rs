@@ -24,8 +24,9 @@ use crate::commands::{
 };
 use crate::{
     commands::{config, contract::install, HEADING_RPC},
-    rpc::{self, Client},
-    utils, wasm,
+    env_file,
+    print::Print,
+    review, rpc, utils, wasm,
 };
 
 #[derive(Parser, Debug, Clone)]
@@ -60,6 +61,10 @@ pub struct Cmd {
     /// configuration without asking for confirmation.
     #[arg(long, value_parser = clap::builder::ValueParser::new(alias_validator))]
     pub alias: Option<String>,
+    /// Shell-sourceable env file to set CONTRACT_ID_<NAME>, RPC_URL and NETWORK_PASSPHRASE in,
+    /// naming the contract after `--alias` or the wasm file, e.g. `--export-env .env`
+    #[arg(long, value_name = "FILE")]
+    pub export_env: Option<std::path::PathBuf>,
 }
 
 #[derive(thiserror::Error, Debug)]
@@ -112,11 +117,18 @@ pub enum Error {
     InvalidAliasFormat { alias: String },
     #[error(transparent)]
     Locator(#[from] locator::Error),
+    #[error(transparent)]
+    Review(#[from] review::Error),
+    #[error(transparent)]
+    EnvFile(#[from] env_file::Error),
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
-        let res = self.run_against_rpc_server(None, None).await?.to_envelope();
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let res = self
+            .run_against_rpc_server(Some(global_args), None)
+            .await?
+            .to_envelope();
         match res {
             TxnEnvelopeResult::TxnEnvelope(tx) => println!("{}", tx.to_xdr_base64(Limits::none())?),
             TxnEnvelopeResult::Res(contract) => {
@@ -129,12 +141,46 @@ impl Cmd {
                         &alias,
                     )?;
                 }
+                if let Some(path) = &self.export_env {
+                    // Export the endpoint that answers rather than the whole list of the RPC URL
+                    let rpc_url = network.rpc_client(&Print::new(global_args)).await?.url;
+                    self.export_env(path, &contract, &rpc_url, &network)?;
+                }
 
                 println!("{contract}");
             }
         }
         Ok(())
     }
+
+    fn export_env(
+        &self,
+        path: &std::path::Path,
+        contract: &str,
+        rpc_url: &str,
+        network: &network::Network,
+    ) -> Result<(), Error> {
+        let name = self.alias.clone().or_else(|| {
+            let wasm = self.wasm.as_ref()?;
+            Some(wasm.file_stem()?.to_string_lossy().into_owned())
+        });
+        let contract_var = match name {
+            Some(name) => env_file::var_name("CONTRACT_ID", &name),
+            None => "CONTRACT_ID".to_string(),
+        };
+        env_file::export(
+            path,
+            &[
+                (contract_var, contract.to_string()),
+                ("RPC_URL".to_string(), rpc_url.to_string()),
+                (
+                    "NETWORK_PASSPHRASE".to_string(),
+                    network.network_passphrase.clone(),
+                ),
+            ],
+        )?;
+        Ok(())
+    }
 }
 
 fn alias_validator(alias: &str) -> Result<String, Error> {
@@ -160,10 +206,14 @@ impl NetworkRunnable for Cmd {
         config: Option<&config::Args>,
     ) -> Result<TxnResult<String>, Error> {
         let config = config.unwrap_or(&self.config);
+        let print = global_args.map(Print::new).unwrap_or_default();
+        let uploading = self.wasm.is_some() && !self.fee.build_only && !self.fee.sim_only;
+        let total = u64::from(uploading) + if self.fee.sim_only { 1 } else { 2 };
         let wasm_hash = if let Some(wasm) = &self.wasm {
             let hash = if self.fee.build_only || self.fee.sim_only {
                 wasm::Args { wasm: wasm.clone() }.hash()?
             } else {
+                print.progressln("deploy", 1, total, "Uploading wasm");
                 install::Cmd {
                     wasm: wasm::Args { wasm: wasm.clone() },
                     config: config.clone(),
@@ -198,17 +248,16 @@ impl NetworkRunnable for Cmd {
             None => rand::thread_rng().gen::<[u8; 32]>(),
         };
 
-        let client = Client::new(&network.rpc_url)?;
+        let client = network.rpc_client(&print).await?;
         client
             .verify_network_passphrase(Some(&network.network_passphrase))
             .await?;
-        let key = config.key_pair()?;
+        let key = config.verifying_key()?;
 
         // Get the account sequence number
-        let public_strkey =
-            stellar_strkey::ed25519::PublicKey(key.verifying_key().to_bytes()).to_string();
+        let public_strkey = stellar_strkey::ed25519::PublicKey(key.to_bytes()).to_string();
 
-        let account_details = client.get_account(&public_strkey).await?;
+        let account_details = client.retry(|| client.get_account(&public_strkey)).await?;
         let sequence: i64 = account_details.seq_num.into();
         let (txn, contract_id) = build_create_contract_tx(
             wasm_hash,
@@ -222,17 +271,33 @@ impl NetworkRunnable for Cmd {
             return Ok(TxnResult::Txn(txn));
         }
 
-        let txn = client.simulate_and_assemble_transaction(&txn).await?;
+        print.progressln(
+            "deploy",
+            u64::from(uploading) + 1,
+            total,
+            "Simulating deploy transaction",
+        );
+        let txn = client
+            .retry(|| client.simulate_and_assemble_transaction(&txn))
+            .await?;
         let txn = self.fee.apply_to_assembled_txn(txn).transaction().clone();
         if self.fee.sim_only {
             return Ok(TxnResult::Txn(txn));
         }
+        review::review(
+            &txn,
+            &network.network_passphrase,
+            &config.locator,
+            self.fee.yes,
+            &print,
+        )?;
+        print.progressln("deploy", total, total, "Submitting deploy transaction");
         let get_txn_resp = client
             .send_transaction_polling(&config.sign_with_local_key(txn).await?)
             .await?
             .try_into()?;
         if global_args.map_or(true, |a| !a.no_cache) {
-            data::write(get_txn_resp, &network.rpc_uri()?)?;
+            data::write(get_txn_resp, &client.uri()?)?;
         }
         Ok(TxnResult::Res(
             stellar_strkey::Contract(contract_id.0).to_string(),
@@ -246,11 +311,9 @@ fn build_create_contract_tx(
     fee: u32,
     network_passphrase: &str,
     salt: [u8; 32],
-    key: &ed25519_dalek::SigningKey,
+    key: &ed25519_dalek::VerifyingKey,
 ) -> Result<(Transaction, Hash), Error> {
-    let source_account = AccountId(PublicKey::PublicKeyTypeEd25519(
-        key.verifying_key().to_bytes().into(),
-    ));
+    let source_account = AccountId(PublicKey::PublicKeyTypeEd25519(key.to_bytes().into()));
 
     let contract_id_preimage = ContractIdPreimage::Address(ContractIdPreimageFromAddress {
         address: ScAddress::Account(source_account),
@@ -269,7 +332,7 @@ fn build_create_contract_tx(
         }),
     };
     let tx = Transaction {
-        source_account: MuxedAccount::Ed25519(Uint256(key.verifying_key().to_bytes())),
+        source_account: MuxedAccount::Ed25519(Uint256(key.to_bytes())),
         fee,
         seq_num: SequenceNumber(sequence),
         cond: Preconditions::None,
@@ -298,7 +361,8 @@ mod tests {
             "Public Global Stellar Network ; September 2015",
             [0u8; 32],
             &utils::parse_secret_key("SBFGFF27Y64ZUGFAIG5AMJGQODZZKV2YQKAVUUN4HNE24XZXD2OEUVUP")
-                .unwrap(),
+                .unwrap()
+                .verifying_key(),
         );
 
         assert!(result.is_ok());
@@ -0,0 +1,361 @@
+use std::{fmt::Write as _, fs, io, path::PathBuf};
+
+use clap::{command, Parser, ValueEnum};
+use soroban_env_host::xdr::{
+    ScMetaEntry, ScMetaV0, ScSpecEntry, ScSpecFunctionV0, ScSpecUdtEnumV0, ScSpecUdtErrorEnumV0,
+    ScSpecUdtStructV0, ScSpecUdtUnionCaseV0, ScSpecUdtUnionV0,
+};
+
+use soroban_spec_tools::type_name;
+
+use crate::{commands::global, print::Print, wasm};
+
+/// Generate documentation for a contract's interface from its WASM file
+///
+/// Renders the functions, types, and doc comments embedded in the contract spec,
+/// so interface documentation can be published straight from the build artifact.
+#[derive(Parser, Debug, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    #[command(flatten)]
+    wasm: wasm::Args,
+    /// Directory to write the documentation to, printed to stdout if omitted
+    #[arg(long)]
+    out: Option<PathBuf>,
+    /// Format of the documentation
+    #[arg(long, value_enum, default_value_t)]
+    format: Format,
+    /// Title of the documentation, defaults to the WASM file name
+    #[arg(long)]
+    title: Option<String>,
+}
+
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq, ValueEnum)]
+pub enum Format {
+    /// Markdown
+    #[default]
+    Markdown,
+    /// A single self-contained HTML page
+    Html,
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Wasm(#[from] wasm::Error),
+    #[error("writing documentation to {0:?}: {1}")]
+    Write(PathBuf, io::Error),
+}
+
+impl Cmd {
+    pub fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
+        let spec = self.wasm.parse()?;
+        let title = self.title.clone().unwrap_or_else(|| {
+            self.wasm
+                .wasm
+                .file_stem()
+                .unwrap_or_default()
+                .to_string_lossy()
+                .to_string()
+        });
+        let blocks = document(&title, &spec.meta, &spec.spec);
+        let (contents, extension) = match self.format {
+            Format::Markdown => (to_markdown(&blocks), "md"),
+            Format::Html => (to_html(&title, &blocks), "html"),
+        };
+        if let Some(out) = &self.out {
+            fs::create_dir_all(out).map_err(|e| Error::Write(out.clone(), e))?;
+            let path = out.join(format!("{title}.{extension}"));
+            fs::write(&path, contents).map_err(|e| Error::Write(path.clone(), e))?;
+            print.saveln(format!("Wrote {}", path.display()));
+        } else {
+            println!("{contents}");
+        }
+        Ok(())
+    }
+}
+
+/// Format-independent pieces of the documentation.
+#[derive(Debug, PartialEq)]
+enum Block {
+    Heading(usize, String),
+    Paragraph(String),
+    Code(String),
+    Table(Vec<&'static str>, Vec<Vec<String>>),
+}
+
+fn document(title: &str, meta: &[ScMetaEntry], spec: &[ScSpecEntry]) -> Vec<Block> {
+    let mut blocks = vec![Block::Heading(1, title.to_string())];
+
+    let meta = meta
+        .iter()
+        .map(|ScMetaEntry::ScMetaV0(ScMetaV0 { key, val })| {
+            vec![key.to_utf8_string_lossy(), val.to_utf8_string_lossy()]
+        })
+        .collect::<Vec<_>>();
+    if !meta.is_empty() {
+        blocks.push(Block::Heading(2, "Meta".to_string()));
+        blocks.push(Block::Table(vec!["Key", "Value"], meta));
+    }
+
+    let functions = spec
+        .iter()
+        .filter_map(|entry| match entry {
+            ScSpecEntry::FunctionV0(func) => Some(func),
+            _ => None,
+        })
+        .collect::<Vec<_>>();
+    if !functions.is_empty() {
+        blocks.push(Block::Heading(2, "Functions".to_string()));
+        for func in functions {
+            document_function(&mut blocks, func);
+        }
+    }
+
+    let types = spec
+        .iter()
+        .filter(|entry| !matches!(entry, ScSpecEntry::FunctionV0(_)))
+        .collect::<Vec<_>>();
+    if !types.is_empty() {
+        blocks.push(Block::Heading(2, "Types".to_string()));
+        for entry in types {
+            match entry {
+                ScSpecEntry::UdtStructV0(udt) => document_struct(&mut blocks, udt),
+                ScSpecEntry::UdtUnionV0(udt) => document_union(&mut blocks, udt),
+                ScSpecEntry::UdtEnumV0(udt) => document_enum(&mut blocks, udt),
+                ScSpecEntry::UdtErrorEnumV0(udt) => document_error(&mut blocks, udt),
+                ScSpecEntry::FunctionV0(_) => {}
+            }
+        }
+    }
+    blocks
+}
+
+fn push_doc(blocks: &mut Vec<Block>, doc: String) {
+    if !doc.is_empty() {
+        blocks.push(Block::Paragraph(doc));
+    }
+}
+
+fn document_function(blocks: &mut Vec<Block>, func: &ScSpecFunctionV0) {
+    let name = func.name.to_utf8_string_lossy();
+    blocks.push(Block::Heading(3, name.clone()));
+    push_doc(blocks, func.doc.to_utf8_string_lossy());
+    let args = func
+        .inputs
+        .iter()
+        .map(|i| format!("{}: {}", i.name.to_utf8_string_lossy(), type_name(&i.type_)))
+        .collect::<Vec<_>>()
+        .join(", ");
+    let output = func
+        .outputs
+        .first()
+        .map(|t| format!(" -> {}", type_name(t)))
+        .unwrap_or_default();
+    blocks.push(Block::Code(format!("fn {name}({args}){output}")));
+    if !func.inputs.is_empty() {
+        blocks.push(Block::Table(
+            vec!["Argument", "Type", "Description"],
+            func.inputs
+                .iter()
+                .map(|i| {
+                    vec![
+                        i.name.to_utf8_string_lossy(),
+                        type_name(&i.type_),
+                        i.doc.to_utf8_string_lossy(),
+                    ]
+                })
+                .collect(),
+        ));
+    }
+}
+
+fn document_struct(blocks: &mut Vec<Block>, udt: &ScSpecUdtStructV0) {
+    blocks.push(Block::Heading(
+        3,
+        format!("struct {}", udt.name.to_utf8_string_lossy()),
+    ));
+    push_doc(blocks, udt.doc.to_utf8_string_lossy());
+    blocks.push(Block::Table(
+        vec!["Field", "Type", "Description"],
+        udt.fields
+            .iter()
+            .map(|f| {
+                vec![
+                    f.name.to_utf8_string_lossy(),
+                    type_name(&f.type_),
+                    f.doc.to_utf8_string_lossy(),
+                ]
+            })
+            .collect(),
+    ));
+}
+
+fn document_union(blocks: &mut Vec<Block>, udt: &ScSpecUdtUnionV0) {
+    blocks.push(Block::Heading(
+        3,
+        format!("union {}", udt.name.to_utf8_string_lossy()),
+    ));
+    push_doc(blocks, udt.doc.to_utf8_string_lossy());
+    blocks.push(Block::Table(
+        vec!["Case", "Values", "Description"],
+        udt.cases
+            .iter()
+            .map(|case| match case {
+                ScSpecUdtUnionCaseV0::VoidV0(case) => vec![
+                    case.name.to_utf8_string_lossy(),
+                    String::new(),
+                    case.doc.to_utf8_string_lossy(),
+                ],
+                ScSpecUdtUnionCaseV0::TupleV0(case) => vec![
+                    case.name.to_utf8_string_lossy(),
+                    case.type_
+                        .iter()
+                        .map(type_name)
+                        .collect::<Vec<_>>()
+                        .join(", "),
+                    case.doc.to_utf8_string_lossy(),
+                ],
+            })
+            .collect(),
+    ));
+}
+
+fn document_enum(blocks: &mut Vec<Block>, udt: &ScSpecUdtEnumV0) {
+    blocks.push(Block::Heading(
+        3,
+        format!("enum {}", udt.name.to_utf8_string_lossy()),
+    ));
+    push_doc(blocks, udt.doc.to_utf8_string_lossy());
+    blocks.push(Block::Table(
+        vec!["Case", "Value", "Description"],
+        udt.cases
+            .iter()
+            .map(|case| {
+                vec![
+                    case.name.to_utf8_string_lossy(),
+                    case.value.to_string(),
+                    case.doc.to_utf8_string_lossy(),
+                ]
+            })
+            .collect(),
+    ));
+}
+
+fn document_error(blocks: &mut Vec<Block>, udt: &ScSpecUdtErrorEnumV0) {
+    blocks.push(Block::Heading(
+        3,
+        format!("error {}", udt.name.to_utf8_string_lossy()),
+    ));
+    push_doc(blocks, udt.doc.to_utf8_string_lossy());
+    blocks.push(Block::Table(
+        vec!["Case", "Code", "Description"],
+        udt.cases
+            .iter()
+            .map(|case| {
+                vec![
+                    case.name.to_utf8_string_lossy(),
+                    case.value.to_string(),
+                    case.doc.to_utf8_string_lossy(),
+                ]
+            })
+            .collect(),
+    ));
+}
+
+fn to_markdown(blocks: &[Block]) -> String {
+    let cell = |s: &str| s.replace('|', "\\|").replace('\n', "<br>");
+    let mut out = String::new();
+    for block in blocks {
+        match block {
+            Block::Heading(level, text) => {
+                let _ = writeln!(out, "{} {text}\n", "#".repeat(*level));
+            }
+            Block::Paragraph(text) => {
+                let _ = writeln!(out, "{text}\n");
+            }
+            Block::Code(code) => {
+                let _ = writeln!(out, "```rust\n{code}\n```\n");
+            }
+            Block::Table(header, rows) => {
+                let _ = writeln!(out, "| {} |", header.join(" | "));
+                let _ = writeln!(out, "|{}", " --- |".repeat(header.len()));
+                for row in rows {
+                    let row = row.iter().map(|c| cell(c)).collect::<Vec<_>>();
+                    let _ = writeln!(out, "| {} |", row.join(" | "));
+                }
+                out.push('\n');
+            }
+        }
+    }
+    out.trim_end().to_string()
+}
+
+fn to_html(title: &str, blocks: &[Block]) -> String {
+    let mut out = format!(
+        "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>{}</title>\n<style>\
+         body{{font-family:sans-serif;max-width:60em;margin:auto;padding:1em}}\
+         table{{border-collapse:collapse}}td,th{{border:1px solid #ccc;padding:.3em .6em;text-align:left}}\
+         pre{{background:#f4f4f4;padding:.6em}}</style>\n</head>\n<body>\n",
+        escape(title)
+    );
+    for block in blocks {
+        match block {
+            Block::Heading(level, text) => {
+                let _ = writeln!(out, "<h{level}>{}</h{level}>", escape(text));
+            }
+            Block::Paragraph(text) => {
+                let _ = writeln!(out, "<p>{}</p>", escape(text).replace('\n', "<br>"));
+            }
+            Block::Code(code) => {
+                let _ = writeln!(out, "<pre><code>{}</code></pre>", escape(code));
+            }
+            Block::Table(header, rows) => {
+                out.push_str("<table>\n<tr>");
+                for h in header {
+                    let _ = write!(out, "<th>{}</th>", escape(h));
+                }
+                out.push_str("</tr>\n");
+                for row in rows {
+                    out.push_str("<tr>");
+                    for c in row {
+                        let _ = write!(out, "<td>{}</td>", escape(c).replace('\n', "<br>"));
+                    }
+                    out.push_str("</tr>\n");
+                }
+                out.push_str("</table>\n");
+            }
+        }
+    }
+    out.push_str("</body>\n</html>");
+    out
+}
+
+fn escape(s: &str) -> String {
+    s.replace('&', "&amp;")
+        .replace('<', "&lt;")
+        .replace('>', "&gt;")
+        .replace('"', "&quot;")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn markdown_tables_escape_pipes() {
+        let md = to_markdown(&[Block::Table(
+            vec!["Argument", "Type", "Description"],
+            vec![vec![
+                "a".to_string(),
+                "u32".to_string(),
+                "x | y".to_string(),
+            ]],
+        )]);
+        assert_eq!(
+            md,
+            "| Argument | Type | Description |\n| --- | --- | --- |\n| a | u32 | x \\| y |"
+        );
+    }
+}
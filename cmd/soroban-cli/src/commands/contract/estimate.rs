@@ -0,0 +1,196 @@
+use std::ffi::OsString;
+
+use clap::{arg, command, Parser, ValueEnum};
+use serde_json::json;
+
+use crate::{
+    commands::{config, global, txn_result::TxnResult, NetworkRunnable},
+    fee,
+    xdr::{SorobanResources, SorobanTransactionData, Transaction, TransactionExt},
+};
+
+use super::invoke;
+
+const STROOPS_PER_XLM: i64 = 10_000_000;
+
+/// Simulate a contract invocation and print the resources it uses and the fees it costs, without
+/// signing or submitting a transaction
+#[derive(Parser, Debug, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// Contract ID to estimate the invocation of
+    #[arg(long = "id", env = "STELLAR_CONTRACT_ID")]
+    pub contract_id: String,
+    /// Format of the estimate
+    #[arg(long, value_enum, default_value = "human")]
+    pub output: Output,
+    /// Function name as subcommand, then arguments for that function as `--arg-name value`
+    #[arg(last = true, id = "CONTRACT_FN_AND_ARGS")]
+    pub slop: Vec<OsString>,
+    #[command(flatten)]
+    pub config: config::Args,
+}
+
+#[derive(Clone, Copy, Debug, Eq, Hash, PartialEq, ValueEnum)]
+pub enum Output {
+    /// Human readable lines
+    Human,
+    /// JSON object
+    Json,
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Invoke(#[from] invoke::Error),
+    #[error("simulation did not return the resources of the invocation")]
+    MissingResources,
+}
+
+/// Resources and fees of a simulated invocation.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct Estimate {
+    pub instructions: u32,
+    pub read_bytes: u32,
+    pub write_bytes: u32,
+    pub read_only_entries: usize,
+    pub read_write_entries: usize,
+    pub resource_fee: i64,
+    pub inclusion_fee: i64,
+}
+
+impl Cmd {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let invoke = invoke::Cmd {
+            contract_id: Some(self.contract_id.clone()),
+            slop: self.slop.clone(),
+            config: self.config.clone(),
+            fee: fee::Args {
+                sim_only: true,
+                ..Default::default()
+            },
+            ..Default::default()
+        };
+        let TxnResult::Txn(tx) = invoke
+            .run_against_rpc_server(Some(global_args), None)
+            .await?
+        else {
+            return Err(Error::MissingResources);
+        };
+        let estimate = Estimate::from_tx(&tx)?;
+        match self.output {
+            Output::Human => {
+                for line in estimate.lines() {
+                    println!("{line}");
+                }
+            }
+            Output::Json => println!("{}", estimate.to_json()),
+        }
+        Ok(())
+    }
+}
+
+impl Estimate {
+    /// Read the estimate from a transaction assembled from its simulation.
+    pub fn from_tx(tx: &Transaction) -> Result<Self, Error> {
+        let TransactionExt::V1(SorobanTransactionData {
+            resources:
+                SorobanResources {
+                    footprint,
+                    instructions,
+                    read_bytes,
+                    write_bytes,
+                },
+            resource_fee,
+            ..
+        }) = &tx.ext
+        else {
+            return Err(Error::MissingResources);
+        };
+        Ok(Estimate {
+            instructions: *instructions,
+            read_bytes: *read_bytes,
+            write_bytes: *write_bytes,
+            read_only_entries: footprint.read_only.len(),
+            read_write_entries: footprint.read_write.len(),
+            resource_fee: *resource_fee,
+            inclusion_fee: i64::from(tx.fee) - resource_fee,
+        })
+    }
+
+    pub fn total_fee(&self) -> i64 {
+        self.resource_fee + self.inclusion_fee
+    }
+
+    fn lines(&self) -> Vec<String> {
+        let total = self.total_fee();
+        vec![
+            format!("Instructions: {}", self.instructions),
+            format!("Read bytes: {}", self.read_bytes),
+            format!("Write bytes: {}", self.write_bytes),
+            format!(
+                "Footprint: {} read-only and {} read-write entries",
+                self.read_only_entries, self.read_write_entries
+            ),
+            format!("Resource fee: {} stroops", self.resource_fee),
+            format!("Inclusion fee: {} stroops", self.inclusion_fee),
+            format!(
+                "Total fee: {total} stroops ({}.{:07} XLM)",
+                total / STROOPS_PER_XLM,
+                total % STROOPS_PER_XLM
+            ),
+        ]
+    }
+
+    fn to_json(self) -> serde_json::Value {
+        json!({
+            "instructions": self.instructions,
+            "read_bytes": self.read_bytes,
+            "write_bytes": self.write_bytes,
+            "read_only_entries": self.read_only_entries,
+            "read_write_entries": self.read_write_entries,
+            "resource_fee": self.resource_fee,
+            "inclusion_fee": self.inclusion_fee,
+            "total_fee": self.total_fee(),
+        })
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::xdr::{
+        ExtensionPoint, LedgerFootprint, Memo, MuxedAccount, Preconditions, SequenceNumber, Uint256,
+    };
+
+    #[test]
+    fn estimate_from_assembled_tx() {
+        let tx = Transaction {
+            source_account: MuxedAccount::Ed25519(Uint256([0; 32])),
+            fee: 12_345,
+            seq_num: SequenceNumber(1),
+            cond: Preconditions::None,
+            memo: Memo::None,
+            operations: vec![].try_into().unwrap(),
+            ext: TransactionExt::V1(SorobanTransactionData {
+                ext: ExtensionPoint::V0,
+                resources: SorobanResources {
+                    footprint: LedgerFootprint {
+                        read_only: vec![].try_into().unwrap(),
+                        read_write: vec![].try_into().unwrap(),
+                    },
+                    instructions: 1_000,
+                    read_bytes: 200,
+                    write_bytes: 30,
+                },
+                resource_fee: 12_245,
+            }),
+        };
+        let estimate = Estimate::from_tx(&tx).unwrap();
+        assert_eq!(estimate.inclusion_fee, 100);
+        assert_eq!(estimate.total_fee(), 12_345);
+        assert!(estimate
+            .lines()
+            .contains(&"Total fee: 12345 stroops (0.0012345 XLM)".to_string()));
+    }
+}
@@ -16,8 +16,8 @@ use crate::{
         NetworkRunnable,
     },
     key,
-    rpc::{self, Client},
-    wasm, Pwd,
+    print::Print,
+    review, rpc, wasm, Pwd,
 };
 
 const MAX_LEDGERS_TO_EXTEND: u32 = 535_679;
@@ -86,12 +86,17 @@ pub enum Error {
     Network(#[from] network::Error),
     #[error(transparent)]
     Locator(#[from] locator::Error),
+    #[error(transparent)]
+    Review(#[from] review::Error),
 }
 
 impl Cmd {
     #[allow(clippy::too_many_lines)]
-    pub async fn run(&self) -> Result<(), Error> {
-        let res = self.run_against_rpc_server(None, None).await?.to_envelope();
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let res = self
+            .run_against_rpc_server(Some(global_args), None)
+            .await?
+            .to_envelope();
         match res {
             TxnEnvelopeResult::TxnEnvelope(tx) => println!("{}", tx.to_xdr_base64(Limits::none())?),
             TxnEnvelopeResult::Res(ttl_ledger) => {
@@ -127,6 +132,7 @@ impl NetworkRunnable for Cmd {
         args: Option<&global::Args>,
         config: Option<&config::Args>,
     ) -> Result<TxnResult<u32>, Self::Error> {
+        let print = args.map(Print::new).unwrap_or_default();
         let config = config.unwrap_or(&self.config);
         let network = config.get_network()?;
         tracing::trace!(?network);
@@ -136,18 +142,17 @@ impl NetworkRunnable for Cmd {
         )?;
         let keys = self.key.parse_keys(contract)?;
         let network = &config.get_network()?;
-        let client = Client::new(&network.rpc_url)?;
-        let key = config.key_pair()?;
+        let client = network.rpc_client(&print).await?;
+        let key = config.verifying_key()?;
         let extend_to = self.ledgers_to_extend();
 
         // Get the account sequence number
-        let public_strkey =
-            stellar_strkey::ed25519::PublicKey(key.verifying_key().to_bytes()).to_string();
-        let account_details = client.get_account(&public_strkey).await?;
+        let public_strkey = stellar_strkey::ed25519::PublicKey(key.to_bytes()).to_string();
+        let account_details = client.retry(|| client.get_account(&public_strkey)).await?;
         let sequence: i64 = account_details.seq_num.into();
 
         let tx = Transaction {
-            source_account: MuxedAccount::Ed25519(Uint256(key.verifying_key().to_bytes())),
+            source_account: MuxedAccount::Ed25519(Uint256(key.to_bytes())),
             fee: self.fee.fee,
             seq_num: SequenceNumber(sequence + 1),
             cond: Preconditions::None,
@@ -182,11 +187,18 @@ impl NetworkRunnable for Cmd {
             .await?
             .transaction()
             .clone();
+        review::review(
+            &tx,
+            &network.network_passphrase,
+            &config.locator,
+            self.fee.yes,
+            &print,
+        )?;
         let res = client
             .send_transaction_polling(&config.sign_with_local_key(tx).await?)
             .await?;
         if args.map_or(true, |a| !a.no_cache) {
-            data::write(res.clone().try_into()?, &network.rpc_uri()?)?;
+            data::write(res.clone().try_into()?, &client.uri()?)?;
         }
 
         let events = res.events()?;
@@ -211,7 +223,9 @@ impl NetworkRunnable for Cmd {
         }
 
         if operations[0].changes.is_empty() {
-            let entry = client.get_full_ledger_entries(&keys).await?;
+            let entry = client
+                .retry(|| client.get_full_ledger_entries(&keys))
+                .await?;
             let extension = entry.entries[0].live_until_ledger_seq;
             if entry.latest_ledger + i64::from(extend_to) < i64::from(extension) {
                 return Ok(TxnResult::Res(extension));
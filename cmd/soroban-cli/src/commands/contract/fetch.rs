@@ -22,10 +22,7 @@ use stellar_strkey::DecodeError;
 use super::super::config::{self, locator};
 use crate::commands::network::{self, Network};
 use crate::commands::{global, NetworkRunnable};
-use crate::{
-    rpc::{self, Client},
-    Pwd,
-};
+use crate::{print::Print, rpc, Pwd};
 
 #[derive(Parser, Debug, Default, Clone)]
 #[allow(clippy::struct_excessive_bools)]
@@ -95,8 +92,8 @@ impl From<Infallible> for Error {
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
-        let bytes = self.get_bytes().await?;
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let bytes = self.run_against_rpc_server(Some(global_args), None).await?;
         if let Some(out_file) = &self.out_file {
             if let Some(parent) = out_file.parent() {
                 if !parent.exists() {
@@ -138,17 +135,20 @@ impl NetworkRunnable for Cmd {
     type Result = Vec<u8>;
     async fn run_against_rpc_server(
         &self,
-        _args: Option<&global::Args>,
+        args: Option<&global::Args>,
         config: Option<&config::Args>,
     ) -> Result<Vec<u8>, Error> {
+        let print = args.map(Print::new).unwrap_or_default();
         let network = config.map_or_else(|| self.network(), |c| Ok(c.get_network()?))?;
         tracing::trace!(?network);
         let contract_id = self.contract_id()?;
-        let client = Client::new(&network.rpc_url)?;
+        let client = network.rpc_client(&print).await?;
         client
             .verify_network_passphrase(Some(&network.network_passphrase))
             .await?;
-        Ok(client.get_remote_wasm(&contract_id).await?)
+        Ok(client
+            .retry(|| client.get_remote_wasm(&contract_id))
+            .await?)
     }
 }
 pub fn get_contract_wasm_from_storage(
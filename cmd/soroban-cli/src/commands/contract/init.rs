@@ -4,8 +4,10 @@ use clap::{
 };
 use gix::{clone, create, open, progress, remote};
 use rust_embed::RustEmbed;
+use serde::Deserialize;
 use serde_json::{from_str, json, to_string_pretty, Error as JsonError, Value as JsonValue};
 use std::{
+    collections::BTreeMap,
     env,
     ffi::OsStr,
     fs::{
@@ -14,13 +16,18 @@ use std::{
     },
     io::{self, Read, Write},
     num::NonZeroU32,
-    path::Path,
+    path::{Path, PathBuf},
     str,
     sync::atomic::AtomicBool,
 };
 use toml_edit::{Document, TomlError};
 use ureq::get;
 
+use crate::{
+    commands::{config::data, global},
+    print::Print,
+};
+
 const SOROBAN_EXAMPLES_URL: &str = "https://github.com/stellar/soroban-examples.git";
 const GITHUB_URL: &str = "https://github.com";
 const WITH_EXAMPLE_LONG_HELP_TEXT: &str =
@@ -35,7 +42,16 @@ pub enum FrontendTemplate {
 #[derive(Parser, Debug, Clone)]
 #[group(skip)]
 pub struct Cmd {
-    pub project_path: String,
+    #[arg(required_unless_present = "list_examples")]
+    pub project_path: Option<String>,
+
+    /// Scaffold the project from an example contract, with its tests, instead of the hello world contract. See --list-examples for the available examples
+    #[arg(long, conflicts_with = "list_examples")]
+    pub from_example: Option<String>,
+
+    /// List the examples that --from-example accepts
+    #[arg(long)]
+    pub list_examples: bool,
 
     #[arg(short, long, num_args = 1.., value_parser=possible_example_values(), long_help=WITH_EXAMPLE_LONG_HELP_TEXT)]
     pub with_example: Vec<String>,
@@ -81,20 +97,114 @@ pub enum Error {
 
     #[error("Failed to convert bytes to string: {0}")]
     ConverBytesToStringErr(#[from] str::Utf8Error),
+
+    #[error("Failed to parse the examples index: {0}")]
+    ExamplesIndexParseError(#[from] toml::de::Error),
+
+    #[error("Unsupported examples index version {0}")]
+    UnsupportedExamplesIndexVersion(u32),
+
+    #[error(
+        "Unknown example {0}, run `contract init --list-examples` to see the available examples"
+    )]
+    UnknownExample(String),
+
+    #[error("Example {0} has not been downloaded before and there is no internet connection")]
+    ExampleNotCached(String),
+
+    #[error("Invalid git ref {0}: {1}")]
+    InvalidGitRef(String, String),
+
+    #[error(transparent)]
+    Data(#[from] data::Error),
 }
 
 impl Cmd {
-    #[allow(clippy::unused_self)]
-    pub fn run(&self) -> Result<(), Error> {
-        println!("ℹ️  Initializing project at {}", self.project_path);
-        let project_path = Path::new(&self.project_path);
+    pub fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
+        if self.list_examples {
+            let index = ExamplesIndex::load()?;
+            for (name, example) in &index.examples {
+                println!("{name:<22} {}", example.description);
+            }
+            return Ok(());
+        }
+        let Some(project_path) = &self.project_path else {
+            return Ok(());
+        };
+        print.infoln(format!("Initializing project at {project_path}"));
 
-        init(project_path, &self.frontend_template, &self.with_example)?;
+        init(
+            Path::new(project_path),
+            &self.frontend_template,
+            &self.with_example,
+            self.from_example.as_deref(),
+            &print,
+        )?;
 
         Ok(())
     }
 }
 
+/// Curated examples that a project can be scaffolded from, embedded in the CLI so that they can be
+/// resolved offline.
+#[derive(Deserialize, Debug)]
+struct ExamplesIndex {
+    version: u32,
+    repository: String,
+    #[serde(rename = "ref")]
+    git_ref: String,
+    soroban_sdk: String,
+    examples: BTreeMap<String, Example>,
+}
+
+#[derive(Deserialize, Debug)]
+struct Example {
+    description: String,
+}
+
+impl ExamplesIndex {
+    fn load() -> Result<Self, Error> {
+        let index: ExamplesIndex = toml::from_str(include_str!("../../../examples_index.toml"))?;
+        if index.version != 1 {
+            return Err(Error::UnsupportedExamplesIndexVersion(index.version));
+        }
+        Ok(index)
+    }
+
+    /// Checkout of the pinned examples release, downloaded once into the data directory and
+    /// reused after that, also when offline.
+    fn checkout(&self, example: &str, print: &Print) -> Result<PathBuf, Error> {
+        if !self.examples.contains_key(example) {
+            return Err(Error::UnknownExample(example.to_string()));
+        }
+        let dir = data::data_local_dir()?.join("examples").join(&self.git_ref);
+        if dir.is_dir() {
+            return Ok(dir);
+        }
+        if !check_internet_connection() {
+            return Err(Error::ExampleNotCached(example.to_string()));
+        }
+        print.infoln(format!(
+            "Downloading soroban-examples {} for soroban-sdk {}",
+            self.git_ref, self.soroban_sdk
+        ));
+        let parent = dir.parent().unwrap();
+        create_dir_all(parent)?;
+        // Clone next to the final location and move it in place once complete, so that an
+        // interrupted download is not mistaken for a cached one
+        let download = tempfile::tempdir_in(parent)?;
+        clone_repo(
+            &self.repository,
+            Some(&self.git_ref),
+            download.path(),
+            print,
+        )?;
+        std::fs::rename(download.into_path(), &dir)?;
+        Ok(dir)
+    }
+}
+
 #[derive(RustEmbed)]
 #[folder = "src/utils/contract-init-template"]
 struct TemplateFiles;
@@ -103,77 +213,97 @@ fn init(
     project_path: &Path,
     frontend_template: &str,
     with_examples: &[String],
+    from_example: Option<&str>,
+    print: &Print,
 ) -> Result<(), Error> {
+    // resolve the example before writing anything, so that a typo doesn't leave a half-made project
+    let example_source = from_example
+        .map(|example| ExamplesIndex::load()?.checkout(example, print))
+        .transpose()?;
+
     // create a project dir, and copy the contents of the base template (contract-init-template) into it
     create_dir_all(project_path).map_err(|e| {
-        eprintln!("Error creating new project directory: {project_path:?}");
+        print.errorln(format!(
+            "Error creating new project directory: {project_path:?}"
+        ));
         e
     })?;
-    copy_template_files(project_path)?;
+    copy_template_files(project_path, from_example.is_none(), print)?;
+
+    if let (Some(example), Some(source)) = (from_example, &example_source) {
+        copy_example_contracts(source, project_path, &[example.to_string()], print)?;
+    }
 
     if !check_internet_connection() {
-        println!("⚠️  It doesn't look like you're connected to the internet. We're still able to initialize a new project, but additional examples and the frontend template will not be included.");
+        print.warnln("It doesn't look like you're connected to the internet. We're still able to initialize a new project, but additional examples and the frontend template will not be included.");
         return Ok(());
     }
 
     if !frontend_template.is_empty() {
         // create a temp dir for the template repo
         let fe_template_dir = tempfile::tempdir().map_err(|e| {
-            eprintln!("Error creating temp dir for frontend template");
+            print.errorln("Error creating temp dir for frontend template");
             e
         })?;
 
         // clone the template repo into the temp dir
-        clone_repo(frontend_template, fe_template_dir.path())?;
+        clone_repo(frontend_template, None, fe_template_dir.path(), print)?;
 
         // copy the frontend template files into the project
-        copy_frontend_files(fe_template_dir.path(), project_path)?;
+        copy_frontend_files(fe_template_dir.path(), project_path, print)?;
     }
 
     // if there are --with-example flags, include the example contracts
     if include_example_contracts(with_examples) {
         // create an examples temp dir
         let examples_dir = tempfile::tempdir().map_err(|e| {
-            eprintln!("Error creating temp dir for soroban-examples");
+            print.errorln("Error creating temp dir for soroban-examples");
             e
         })?;
 
         // clone the soroban-examples repo into the temp dir
-        clone_repo(SOROBAN_EXAMPLES_URL, examples_dir.path())?;
+        clone_repo(SOROBAN_EXAMPLES_URL, None, examples_dir.path(), print)?;
 
         // copy the example contracts into the project
-        copy_example_contracts(examples_dir.path(), project_path, with_examples)?;
+        copy_example_contracts(examples_dir.path(), project_path, with_examples, print)?;
     }
 
     Ok(())
 }
 
-fn copy_template_files(project_path: &Path) -> Result<(), Error> {
+fn copy_template_files(
+    project_path: &Path,
+    with_hello_world: bool,
+    print: &Print,
+) -> Result<(), Error> {
     for item in TemplateFiles::iter() {
+        if !with_hello_world && item.starts_with("contracts/hello_world/") {
+            continue;
+        }
         let mut to = project_path.join(item.as_ref());
 
         if file_exists(&to) {
-            println!(
-                "ℹ️  Skipped creating {} as it already exists",
+            print.infoln(format!(
+                "Skipped creating {} as it already exists",
                 &to.to_string_lossy()
-            );
+            ));
             continue;
         }
         create_dir_all(to.parent().unwrap()).map_err(|e| {
-            eprintln!("Error creating directory path for: {to:?}");
+            print.errorln(format!("Error creating directory path for: {to:?}"));
             e
         })?;
 
         let Some(file) = TemplateFiles::get(item.as_ref()) else {
-            println!("⚠️  Failed to read file: {}", item.as_ref());
+            print.warnln(format!("Failed to read file: {}", item.as_ref()));
             continue;
         };
 
         let file_contents = std::str::from_utf8(file.data.as_ref()).map_err(|e| {
-            eprintln!(
+            print.errorln(format!(
                 "Error converting file contents in {:?} to string",
                 item.as_ref()
-            );
+            ));
             e
         })?;
 
@@ -184,16 +314,16 @@ fn copy_template_files(project_path: &Path) -> Result<(), Error> {
             to = project_path.join(item_parent_path).join("Cargo.toml");
         }
 
-        println!("➕  Writing {}", &to.to_string_lossy());
+        print.plusln(format!("Writing {}", &to.to_string_lossy()));
         write(&to, file_contents).map_err(|e| {
-            eprintln!("Error writing file: {to:?}");
+            print.errorln(format!("Error writing file: {to:?}"));
             e
         })?;
     }
     Ok(())
 }
 
-fn copy_contents(from: &Path, to: &Path) -> Result<(), Error> {
+fn copy_contents(from: &Path, to: &Path, print: &Print) -> Result<(), Error> {
     let contents_to_exclude_from_copy = [
         ".git",
         ".github",
@@ -203,11 +333,11 @@ fn copy_contents(from: &Path, to: &Path) -> Result<(), Error> {
         "Cargo.lock",
     ];
     for entry in read_dir(from).map_err(|e| {
-        eprintln!("Error reading directory: {from:?}");
+        print.errorln(format!("Error reading directory: {from:?}"));
         e
     })? {
         let entry = entry.map_err(|e| {
-            eprintln!("Error reading entry in directory: {from:?}");
+            print.errorln(format!("Error reading entry in directory: {from:?}"));
             e
         })?;
         let path = entry.path();
@@ -220,33 +350,33 @@ fn copy_contents(from: &Path, to: &Path) -> Result<(), Error> {
 
         if path.is_dir() {
             create_dir_all(&new_path).map_err(|e| {
-                eprintln!("Error creating directory: {new_path:?}");
+                print.errorln(format!("Error creating directory: {new_path:?}"));
                 e
             })?;
-            copy_contents(&path, &new_path)?;
+            copy_contents(&path, &new_path, print)?;
         } else {
             if file_exists(&new_path) {
                 if new_path.to_string_lossy().contains(".gitignore") {
-                    append_contents(&path, &new_path)?;
+                    append_contents(&path, &new_path, print)?;
                 }
                 if new_path.to_string_lossy().contains("README.md") {
-                    append_contents(&path, &new_path)?;
+                    append_contents(&path, &new_path, print)?;
                 }
 
-                println!(
-                    "ℹ️  Skipped creating {} as it already exists",
+                print.infoln(format!(
+                    "Skipped creating {} as it already exists",
                     &new_path.to_string_lossy()
-                );
+                ));
                 continue;
             }
 
-            println!("➕  Writing {}", &new_path.to_string_lossy());
+            print.plusln(format!("Writing {}", &new_path.to_string_lossy()));
             copy(&path, &new_path).map_err(|e| {
-                eprintln!(
+                print.errorln(format!(
                     "Error copying from {:?} to {:?}",
                     path.to_string_lossy(),
                     new_path
-                );
+                ));
                 e
             })?;
         }
@@ -266,7 +396,12 @@ fn include_example_contracts(contracts: &[String]) -> bool {
     !contracts.is_empty()
 }
 
-fn clone_repo(from_url: &str, to_path: &Path) -> Result<(), Error> {
+fn clone_repo(
+    from_url: &str,
+    git_ref: Option<&str>,
+    to_path: &Path,
+    print: &Print,
+) -> Result<(), Error> {
     let mut prepare = clone::PrepareFetch::new(
         from_url,
         to_path,
@@ -278,54 +413,65 @@ fn clone_repo(from_url: &str, to_path: &Path) -> Result<(), Error> {
         open::Options::isolated(),
     )
     .map_err(|e| {
-        eprintln!("Error preparing fetch for {from_url:?}");
+        print.errorln(format!("Error preparing fetch for {from_url:?}"));
         Box::new(e)
     })?
     .with_shallow(remote::fetch::Shallow::DepthAtRemote(
         NonZeroU32::new(1).unwrap(),
-    ));
+    ))
+    .with_ref_name(git_ref)
+    .map_err(|e| Error::InvalidGitRef(git_ref.unwrap_or_default().to_string(), e.to_string()))?;
 
     let (mut checkout, _outcome) = prepare
         .fetch_then_checkout(progress::Discard, &AtomicBool::new(false))
         .map_err(|e| {
-            eprintln!("Error calling fetch_then_checkout with {from_url:?}");
+            print.errorln(format!(
+                "Error calling fetch_then_checkout with {from_url:?}"
+            ));
             Box::new(e)
         })?;
 
     let (_repo, _outcome) = checkout
         .main_worktree(progress::Discard, &AtomicBool::new(false))
         .map_err(|e| {
-            eprintln!("Error calling main_worktree for {from_url:?}");
+            print.errorln(format!("Error calling main_worktree for {from_url:?}"));
             e
         })?;
 
     Ok(())
 }
 
-fn copy_example_contracts(from: &Path, to: &Path, contracts: &[String]) -> Result<(), Error> {
+fn copy_example_contracts(
+    from: &Path,
+    to: &Path,
+    contracts: &[String],
+    print: &Print,
+) -> Result<(), Error> {
     let project_contracts_path = to.join("contracts");
     for contract in contracts {
-        println!("ℹ️  Initializing example contract: {contract}");
+        print.infoln(format!("Initializing example contract: {contract}"));
         let contract_as_string = contract.to_string();
         let contract_path = Path::new(&contract_as_string);
         let from_contract_path = from.join(contract_path);
         let to_contract_path = project_contracts_path.join(contract_path);
         create_dir_all(&to_contract_path).map_err(|e| {
-            eprintln!("Error creating directory: {contract_path:?}");
+            print.errorln(format!("Error creating directory: {contract_path:?}"));
             e
         })?;
 
-        copy_contents(&from_contract_path, &to_contract_path)?;
-        edit_contract_cargo_file(&to_contract_path)?;
+        copy_contents(&from_contract_path, &to_contract_path, print)?;
+        edit_contract_cargo_file(&to_contract_path, print)?;
     }
 
     Ok(())
 }
 
-fn edit_contract_cargo_file(contract_path: &Path) -> Result<(), Error> {
+fn edit_contract_cargo_file(contract_path: &Path, print: &Print) -> Result<(), Error> {
     let cargo_path = contract_path.join("Cargo.toml");
     let cargo_toml_str = read_to_string(&cargo_path).map_err(|e| {
-        eprint!("Error reading Cargo.toml file in: {contract_path:?}");
+        print.errorln(format!(
+            "Error reading Cargo.toml file in: {contract_path:?}"
+        ));
         e
     })?;
 
@@ -341,26 +487,30 @@ fn edit_contract_cargo_file(contract_path: &Path) -> Result<(), Error> {
         .replace_all(&cargo_toml_str, "soroban-sdk = {$1 workspace = true$2}");
 
     let mut doc = cargo_toml_str.parse::<Document>().map_err(|e| {
-        eprintln!("Error parsing Cargo.toml file in: {contract_path:?}");
+        print.errorln(format!(
+            "Error parsing Cargo.toml file in: {contract_path:?}"
+        ));
         e
     })?;
     doc.remove("profile");
 
     write(&cargo_path, doc.to_string()).map_err(|e| {
-        eprintln!("Error writing to Cargo.toml file in: {contract_path:?}");
+        print.errorln(format!(
+            "Error writing to Cargo.toml file in: {contract_path:?}"
+        ));
         e
     })?;
 
     Ok(())
 }
 
-fn copy_frontend_files(from: &Path, to: &Path) -> Result<(), Error> {
-    println!("ℹ️  Initializing with frontend template");
-    copy_contents(from, to)?;
-    edit_package_json_files(to)
+fn copy_frontend_files(from: &Path, to: &Path, print: &Print) -> Result<(), Error> {
+    print.infoln("Initializing with frontend template");
+    copy_contents(from, to, print)?;
+    edit_package_json_files(to, print)
 }
 
-fn edit_package_json_files(project_path: &Path) -> Result<(), Error> {
+fn edit_package_json_files(project_path: &Path, print: &Print) -> Result<(), Error> {
     let package_name = if let Some(name) = project_path.file_name() {
         name.to_owned()
     } else {
@@ -372,23 +522,28 @@ fn edit_package_json_files(project_path: &Path) -> Result<(), Error> {
         file_name
     };
 
-    edit_package_name(project_path, &package_name, "package.json").map_err(|e| {
-        eprintln!("Error editing package.json file in: {project_path:?}");
+    edit_package_name(project_path, &package_name, "package.json", print).map_err(|e| {
+        print.errorln(format!(
+            "Error editing package.json file in: {project_path:?}"
+        ));
         e
     })?;
-    edit_package_name(project_path, &package_name, "package-lock.json")
+    edit_package_name(project_path, &package_name, "package-lock.json", print)
 }
 
 fn edit_package_name(
     project_path: &Path,
     package_name: &OsStr,
     file_name: &str,
+    print: &Print,
 ) -> Result<(), Error> {
     let file_path = project_path.join(file_name);
     let file_contents = read_to_string(&file_path)?;
 
     let mut doc: JsonValue = from_str(&file_contents).map_err(|e| {
-        eprintln!("Error parsing package.json file in: {project_path:?}");
+        print.errorln(format!(
+            "Error parsing package.json file in: {project_path:?}"
+        ));
         e
     })?;
 
@@ -410,7 +565,7 @@ fn check_internet_connection() -> bool {
 }
 
 // Appends the contents of a file to another file, separated by a delimiter
-fn append_contents(from: &Path, to: &Path) -> Result<(), Error> {
+fn append_contents(from: &Path, to: &Path, print: &Print) -> Result<(), Error> {
     let mut from_file = File::open(from)?;
     let mut from_content = String::new();
     from_file.read_to_string(&mut from_content)?;
@@ -428,7 +583,7 @@ fn append_contents(from: &Path, to: &Path) -> Result<(), Error> {
     to_file.write_all(delimiter.as_bytes())?;
     to_file.write_all(from_content.as_bytes())?;
 
-    println!("ℹ️  Merging {} contents", &to.to_string_lossy());
+    print.infoln(format!("Merging {} contents", &to.to_string_lossy()));
     Ok(())
 }
 
@@ -458,7 +613,14 @@ mod tests {
         let temp_dir = tempfile::tempdir().unwrap();
         let project_dir = temp_dir.path().join(TEST_PROJECT_NAME);
         let with_examples = vec![];
-        init(project_dir.as_path(), "", &with_examples).unwrap();
+        init(
+            project_dir.as_path(),
+            "",
+            &with_examples,
+            None,
+            &Print::default(),
+        )
+        .unwrap();
 
         assert_base_template_files_exist(&project_dir);
         assert_default_hello_world_contract_files_exist(&project_dir);
@@ -476,7 +638,14 @@ mod tests {
         let temp_dir = tempfile::tempdir().unwrap();
         let project_dir = temp_dir.path().join(TEST_PROJECT_NAME);
         let with_examples = ["alloc".to_owned()];
-        init(project_dir.as_path(), "", &with_examples).unwrap();
+        init(
+            project_dir.as_path(),
+            "",
+            &with_examples,
+            None,
+            &Print::default(),
+        )
+        .unwrap();
 
         assert_base_template_files_exist(&project_dir);
         assert_default_hello_world_contract_files_exist(&project_dir);
@@ -499,7 +668,14 @@ mod tests {
         let temp_dir = tempfile::tempdir().unwrap();
         let project_dir = temp_dir.path().join("project");
         let with_examples = ["account".to_owned(), "atomic_swap".to_owned()];
-        init(project_dir.as_path(), "", &with_examples).unwrap();
+        init(
+            project_dir.as_path(),
+            "",
+            &with_examples,
+            None,
+            &Print::default(),
+        )
+        .unwrap();
 
         assert_base_template_files_exist(&project_dir);
         assert_default_hello_world_contract_files_exist(&project_dir);
@@ -523,11 +699,29 @@ mod tests {
         let temp_dir = tempfile::tempdir().unwrap();
         let project_dir = temp_dir.path().join("project");
         let with_examples = ["invalid_example".to_owned(), "atomic_swap".to_owned()];
-        assert!(init(project_dir.as_path(), "", &with_examples,).is_err());
+        assert!(init(
+            project_dir.as_path(),
+            "",
+            &with_examples,
+            None,
+            &Print::default()
+        )
+        .is_err());
 
         temp_dir.close().unwrap();
     }
 
+    #[test]
+    fn test_examples_index() {
+        let index = ExamplesIndex::load().unwrap();
+        assert!(index.examples.contains_key("increment"));
+        assert!(index.examples.values().all(|e| !e.description.is_empty()));
+        assert!(matches!(
+            index.checkout("invalid_example"),
+            Err(Error::UnknownExample(_))
+        ));
+    }
+
     #[test]
     fn test_init_with_frontend_template() {
         let temp_dir = tempfile::tempdir().unwrap();
@@ -537,6 +731,8 @@ mod tests {
             project_dir.as_path(),
             "https://github.com/stellar/soroban-astro-template",
             &with_examples,
+            None,
+            &Print::default(),
         )
         .unwrap();
 
@@ -565,6 +761,8 @@ mod tests {
             project_dir.as_path(),
             "https://github.com/stellar/soroban-astro-template",
             &with_examples,
+            None,
+            &Print::default(),
         )
         .unwrap();
 
@@ -595,6 +793,8 @@ mod tests {
             project_dir.as_path(),
             "https://github.com/stellar/soroban-astro-template",
             &with_examples,
+            None,
+            &Print::default(),
         )
         .unwrap();
 
@@ -603,6 +803,8 @@ mod tests {
             project_dir.as_path(),
             "https://github.com/stellar/soroban-astro-template",
             &with_examples,
+            None,
+            &Print::default(),
         )
         .unwrap();
 
@@ -15,7 +15,9 @@ use crate::commands::network;
 use crate::commands::txn_result::{TxnEnvelopeResult, TxnResult};
 use crate::commands::{config::data, global, NetworkRunnable};
 use crate::key;
-use crate::rpc::{self, Client};
+use crate::print::Print;
+use crate::review;
+use crate::rpc;
 use crate::{commands::config, utils, wasm};
 
 const CONTRACT_META_SDK_KEY: &str = "rssdkver";
@@ -69,11 +71,16 @@ pub enum Error {
     Network(#[from] network::Error),
     #[error(transparent)]
     Data(#[from] data::Error),
+    #[error(transparent)]
+    Review(#[from] review::Error),
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
-        let res = self.run_against_rpc_server(None, None).await?.to_envelope();
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let res = self
+            .run_against_rpc_server(Some(global_args), None)
+            .await?
+            .to_envelope();
         match res {
             TxnEnvelopeResult::TxnEnvelope(tx) => println!("{}", tx.to_xdr_base64(Limits::none())?),
             TxnEnvelopeResult::Res(hash) => println!("{}", hex::encode(hash)),
@@ -91,10 +98,11 @@ impl NetworkRunnable for Cmd {
         args: Option<&global::Args>,
         config: Option<&config::Args>,
     ) -> Result<TxnResult<Hash>, Error> {
+        let print = args.map(Print::new).unwrap_or_default();
         let config = config.unwrap_or(&self.config);
         let contract = self.wasm.read()?;
         let network = config.get_network()?;
-        let client = Client::new(&network.rpc_url)?;
+        let client = network.rpc_client(&print).await?;
         client
             .verify_network_passphrase(Some(&network.network_passphrase))
             .await?;
@@ -118,12 +126,11 @@ impl NetworkRunnable for Cmd {
                 tracing::warn!("the deployed smart contract {path} was built with Soroban Rust SDK v{rs_sdk_ver}, a release candidate version not intended for use with the Stellar Public Network", path = self.wasm.wasm.display());
             }
         }
-        let key = config.key_pair()?;
+        let key = config.verifying_key()?;
 
         // Get the account sequence number
-        let public_strkey =
-            stellar_strkey::ed25519::PublicKey(key.verifying_key().to_bytes()).to_string();
-        let account_details = client.get_account(&public_strkey).await?;
+        let public_strkey = stellar_strkey::ed25519::PublicKey(key.to_bytes()).to_string();
+        let account_details = client.retry(|| client.get_account(&public_strkey)).await?;
         let sequence: i64 = account_details.seq_num.into();
 
         let (tx_without_preflight, hash) =
@@ -136,9 +143,12 @@ impl NetworkRunnable for Cmd {
         // has requested to perform simulation only and is hoping to get a
         // transaction back.
         if !self.fee.sim_only {
-            let code_key =
-                xdr::LedgerKey::ContractCode(xdr::LedgerKeyContractCode { hash: hash.clone() });
-            let contract_data = client.get_ledger_entries(&[code_key]).await?;
+            let code_keys = [xdr::LedgerKey::ContractCode(xdr::LedgerKeyContractCode {
+                hash: hash.clone(),
+            })];
+            let contract_data = client
+                .retry(|| client.get_ledger_entries(&code_keys))
+                .await?;
             // Skip install if the contract is already installed, and the contract has an extension version that isn't V0.
             // In protocol 21 extension V1 was added that stores additional information about a contract making execution
             // of the contract cheaper. So if folks want to reinstall we should let them which is why the install will still
@@ -170,11 +180,18 @@ impl NetworkRunnable for Cmd {
         if self.fee.sim_only {
             return Ok(TxnResult::Txn(txn));
         }
+        review::review(
+            &txn,
+            &network.network_passphrase,
+            &config.locator,
+            self.fee.yes,
+            &print,
+        )?;
         let txn_resp = client
             .send_transaction_polling(&self.config.sign_with_local_key(txn).await?)
             .await?;
         if args.map_or(true, |a| !a.no_cache) {
-            data::write(txn_resp.clone().try_into().unwrap(), &network.rpc_uri()?)?;
+            data::write(txn_resp.clone().try_into().unwrap(), &client.uri()?)?;
         }
         // Currently internal errors are not returned if the contract code is expired
         if let Some(TransactionResult {
@@ -231,14 +248,12 @@ pub(crate) fn build_install_contract_code_tx(
     source_code: &[u8],
     sequence: i64,
     fee: u32,
-    key: &ed25519_dalek::SigningKey,
+    key: &ed25519_dalek::VerifyingKey,
 ) -> Result<(Transaction, Hash), XdrError> {
     let hash = utils::contract_hash(source_code)?;
 
     let op = Operation {
-        source_account: Some(MuxedAccount::Ed25519(Uint256(
-            key.verifying_key().to_bytes(),
-        ))),
+        source_account: Some(MuxedAccount::Ed25519(Uint256(key.to_bytes()))),
         body: OperationBody::InvokeHostFunction(InvokeHostFunctionOp {
             host_function: HostFunction::UploadContractWasm(source_code.try_into()?),
             auth: VecM::default(),
@@ -246,7 +261,7 @@ pub(crate) fn build_install_contract_code_tx(
     };
 
     let tx = Transaction {
-        source_account: MuxedAccount::Ed25519(Uint256(key.verifying_key().to_bytes())),
+        source_account: MuxedAccount::Ed25519(Uint256(key.to_bytes())),
         fee,
         seq_num: SequenceNumber(sequence),
         cond: Preconditions::None,
@@ -269,7 +284,8 @@ mod tests {
             300,
             1,
             &utils::parse_secret_key("SBFGFF27Y64ZUGFAIG5AMJGQODZZKV2YQKAVUUN4HNE24XZXD2OEUVUP")
-                .unwrap(),
+                .unwrap()
+                .verifying_key(),
         );
 
         assert!(result.is_ok());
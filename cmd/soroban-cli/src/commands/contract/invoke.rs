@@ -4,19 +4,25 @@ use std::ffi::OsString;
 use std::num::ParseIntError;
 use std::path::{Path, PathBuf};
 use std::str::FromStr;
-use std::{fmt::Debug, fs, io};
+use std::{
+    fmt::Debug,
+    fs,
+    io::{self, IsTerminal},
+};
 
-use clap::{arg, command, value_parser, Parser};
+use base64::Engine as _;
+use clap::{arg, command, parser::ValueSource, value_parser, Parser, ValueEnum};
 use ed25519_dalek::SigningKey;
 use heck::ToKebabCase;
 
 use soroban_env_host::{
     xdr::{
-        self, AccountEntry, AccountEntryExt, AccountId, Hash, HostFunction, InvokeContractArgs,
-        InvokeHostFunctionOp, LedgerEntryData, Limits, Memo, MuxedAccount, Operation,
-        OperationBody, Preconditions, PublicKey, ScAddress, ScSpecEntry, ScSpecFunctionV0,
-        ScSpecTypeDef, ScVal, ScVec, SequenceNumber, String32, StringM, Thresholds, Transaction,
-        TransactionExt, Uint256, VecM, WriteXdr,
+        self, AccountEntry, AccountEntryExt, AccountId, ContractEventType, DiagnosticEvent,
+        ExtensionPoint, Hash, HostFunction, InvokeContractArgs, InvokeHostFunctionOp,
+        LedgerEntryData, Limits, Memo, MuxedAccount, Operation, OperationBody, Preconditions,
+        PublicKey, ReadXdr, RestoreFootprintOp, ScAddress, ScSpecEntry, ScSpecFunctionV0,
+        ScSpecTypeDef, ScVal, ScVec, SequenceNumber, SorobanTransactionData, String32, StringM,
+        Thresholds, Transaction, TransactionExt, Uint256, VecM, WriteXdr,
     },
     HostError,
 };
@@ -31,24 +37,67 @@ use crate::commands::txn_result::{TxnEnvelopeResult, TxnResult};
 use crate::commands::NetworkRunnable;
 use crate::get_spec::{self, get_remote_contract_spec};
 use crate::{
-    commands::{config::data, global, network},
-    rpc, Pwd,
+    commands::{address, config::data, global, network},
+    print::Print,
+    review, rpc, Pwd,
 };
 use soroban_spec_tools::{contract, Spec};
 
+pub mod batch;
+
 #[derive(Parser, Debug, Default, Clone)]
 #[allow(clippy::struct_excessive_bools)]
 #[group(skip)]
 pub struct Cmd {
     /// Contract ID to invoke
-    #[arg(long = "id", env = "STELLAR_CONTRACT_ID")]
-    pub contract_id: String,
+    #[arg(
+        long = "id",
+        env = "STELLAR_CONTRACT_ID",
+        required_unless_present = "batch"
+    )]
+    pub contract_id: Option<String>,
     // For testing only
     #[arg(skip)]
     pub wasm: Option<std::path::PathBuf>,
-    /// View the result simulating and do not sign and submit transaction
-    #[arg(long, env = "STELLAR_INVOKE_VIEW")]
+    /// File with the contract spec to parse the arguments with, instead of fetching the contract's
+    /// wasm. Either the output of `contract inspect --output xdr-base64` or `--output
+    /// xdr-base64-array`, a JSON array of spec entries, or the raw XDR of the entries
+    #[arg(long, value_name = "FILE")]
+    pub spec: Option<PathBuf>,
+    /// View the result simulating and do not sign and submit transaction. Same as `--send=no`
+    #[arg(long, env = "STELLAR_INVOKE_VIEW", conflicts_with = "send")]
     pub is_view: bool,
+    /// Whether to send the transaction, or only print the result of simulating it
+    #[arg(long, value_enum, default_value_t, env = "STELLAR_SEND")]
+    pub send: ShouldSend,
+    /// Restore archived ledger entries the invocation needs with a separate transaction, then
+    /// invoke
+    #[arg(long, conflicts_with = "is_view")]
+    pub restore: bool,
+    /// JSON file, prefixed with `@`, containing an object that maps argument names to their
+    /// JSON values, e.g. `--arg @args.json`. Arguments given after `--` take precedence
+    #[arg(long = "arg", value_name = "@FILE")]
+    pub arg_file: Option<String>,
+    /// JSON or CSV file of invocations to run instead of a single one, e.g. `--batch jobs.json`
+    ///
+    /// A JSON file holds an array of jobs such as `{"id": "C...", "source": "alice", "fn":
+    /// "transfer", "args": {"to": "bob", "amount": "100"}}`, where `id` and `source` default to
+    /// `--id` and `--source-account`. A CSV file has a header row with `id`, `source` and `fn`
+    /// columns, and every other column is an argument.
+    #[arg(long, conflicts_with_all = ["CONTRACT_FN_AND_ARGS", "arg_file"])]
+    pub batch: Option<PathBuf>,
+    /// File the result of each job is appended to as a JSON line, defaults to the batch file with
+    /// a `.results.jsonl` extension. Jobs it records as succeeded are skipped, so an interrupted or
+    /// partly failed batch is resumed by running it again
+    #[arg(long, requires = "batch")]
+    pub batch_results: Option<PathBuf>,
+    /// Maximum number of batch jobs to run at once. Jobs with the same source account always run
+    /// one after another. Above 1 it needs --yes or --send, as jobs can't be confirmed at once
+    #[arg(long, requires = "batch", default_value = "1")]
+    pub concurrency: usize,
+    /// Arguments of a batch job, in place of the arguments file
+    #[arg(skip)]
+    pub args: Option<serde_json::Map<String, serde_json::Value>>,
     /// Function name as subcommand, then arguments for that function as `--arg-name value`
     #[arg(last = true, id = "CONTRACT_FN_AND_ARGS")]
     pub slop: Vec<OsString>,
@@ -138,6 +187,18 @@ pub enum Error {
     ContractSpec(#[from] contract::Error),
     #[error("")]
     MissingFileArg(PathBuf),
+    #[error("reading arguments file {0:?}: {1}")]
+    CannotReadArgFile(PathBuf, io::Error),
+    #[error("parsing arguments file {0:?}: {1}")]
+    CannotParseArgFile(PathBuf, serde_json::Error),
+    #[error("reading spec file {0:?}: {1}")]
+    CannotReadSpecFile(PathBuf, io::Error),
+    #[error("parsing spec file {0:?}: {1}")]
+    CannotParseSpecFile(PathBuf, String),
+    #[error("arguments file {0:?} must contain a JSON object of argument names to values")]
+    ArgFileNotObject(PathBuf),
+    #[error("argument {0} in arguments file is not an argument of function {1}")]
+    UnknownArgInFile(String, String),
     #[error(transparent)]
     Io(#[from] std::io::Error),
     #[error(transparent)]
@@ -146,6 +207,16 @@ pub enum Error {
     Network(#[from] network::Error),
     #[error(transparent)]
     GetSpecError(#[from] get_spec::Error),
+    #[error("missing contract id, pass it with --id")]
+    MissingContractId,
+    #[error(transparent)]
+    Batch(#[from] batch::Error),
+    #[error(transparent)]
+    Review(#[from] review::Error),
+    #[error("the invocation needs archived ledger entries, pass --restore to restore them first")]
+    ArchivedEntries,
+    #[error("the invocation writes to the ledger and there is no terminal to confirm sending it on, pass --send=yes or --yes to send it")]
+    SendNotConfirmed,
 }
 
 impl From<Infallible> for Error {
@@ -154,6 +225,18 @@ impl From<Infallible> for Error {
     }
 }
 
+#[derive(Clone, Copy, Debug, Default, Eq, Hash, PartialEq, ValueEnum)]
+pub enum ShouldSend {
+    /// Send the transaction if simulation shows it writes to the ledger, after confirming it on the
+    /// terminal, or fail without one unless --yes is given. Otherwise only print the simulated result
+    #[default]
+    Default,
+    /// Only print the simulated result
+    No,
+    /// Send the transaction without confirming it, even if it only reads from the ledger
+    Yes,
+}
+
 impl Cmd {
     fn is_view(&self) -> bool {
         self.is_view ||
@@ -163,6 +246,12 @@ impl Cmd {
             std::env::var("SYSTEM_TEST_VERBOSE_OUTPUT").as_deref() == Ok("true")
     }
 
+    /// Whether sending was already confirmed on the command line, so the transaction is not
+    /// reviewed.
+    fn confirmed(&self) -> bool {
+        self.fee.yes || self.send == ShouldSend::Yes
+    }
+
     fn build_host_function_parameters(
         &self,
         contract_id: [u8; 32],
@@ -170,7 +259,7 @@ impl Cmd {
         config: &config::Args,
     ) -> Result<(String, Spec, InvokeContractArgs, Vec<SigningKey>), Error> {
         let spec = Spec(Some(spec_entries.to_vec()));
-        let mut cmd = clap::Command::new(self.contract_id.clone())
+        let mut cmd = clap::Command::new(self.contract_id.clone().unwrap_or_default())
             .no_binary_name(true)
             .term_width(300)
             .max_term_width(300);
@@ -179,6 +268,15 @@ impl Cmd {
             cmd = cmd.subcommand(build_custom_cmd(&name.to_utf8_string_lossy(), &spec)?);
         }
         cmd.build();
+        if self.args.is_some() {
+            // Batch jobs name their function directly, and clap would exit the whole batch on an
+            // unknown one
+            if let Some(function) = self.slop.first() {
+                let function = function.to_string_lossy();
+                spec.find_function(&function)
+                    .map_err(|_| Error::FunctionNotFoundInContractSpec(function.to_string()))?;
+            }
+        }
         let long_help = cmd.render_long_help();
         let mut matches_ = cmd.get_matches_from(&self.slop);
         let Some((function, matches_)) = &matches_.remove_subcommand() else {
@@ -187,6 +285,15 @@ impl Cmd {
         };
 
         let func = spec.find_function(function)?;
+        let file_args = self.read_arg_file()?;
+        if let Some(unknown) = file_args.keys().find(|k| {
+            !func
+                .inputs
+                .iter()
+                .any(|i| i.name.to_utf8_string_lossy() == **k)
+        }) {
+            return Err(Error::UnknownArgInFile(unknown.clone(), function.clone()));
+        }
         // create parsed_args in same order as the inputs to func
         let mut signers: Vec<SigningKey> = vec![];
         let parsed_args = func
@@ -194,48 +301,36 @@ impl Cmd {
             .iter()
             .map(|i| {
                 let name = i.name.to_utf8_string()?;
-                if let Some(mut val) = matches_.get_raw(&name) {
+                // Bools always have a default value, which should not shadow the arguments file
+                let is_default = matches_.value_source(&name) == Some(ValueSource::DefaultValue);
+                if let Some(mut val) = matches_
+                    .get_raw(&name)
+                    .filter(|_| !is_default || !file_args.contains_key(&name))
+                {
                     let mut s = val.next().unwrap().to_string_lossy().to_string();
-                    if matches!(i.type_, ScSpecTypeDef::Address) {
-                        let cmd = crate::commands::keys::address::Cmd {
-                            name: s.clone(),
-                            hd_path: Some(0),
-                            locator: config.locator.clone(),
-                        };
-                        if let Ok(address) = cmd.public_key() {
-                            s = address.to_string();
-                        }
-                        if let Ok(key) = cmd.private_key() {
-                            signers.push(key);
+                    if let Some(path) = s.strip_prefix('@').filter(|p| !p.starts_with('@')) {
+                        return parse_file_arg(&spec, &name, &i.type_, Path::new(path));
+                    }
+                    if s.starts_with("@@") {
+                        s.remove(0);
+                    }
+                    parse_string_arg(&spec, name, &i.type_, &s, config, &mut signers)
+                } else if let Some(value) = file_args.get(&name) {
+                    match value {
+                        // Batch job values are written the way they would be on the command line
+                        serde_json::Value::String(s) if self.args.is_some() => {
+                            parse_string_arg(&spec, name, &i.type_, s, config, &mut signers)
                         }
+                        _ => spec
+                            .from_json(value, &i.type_)
+                            .map_err(|error| Error::CannotParseArg { arg: name, error }),
                     }
-                    spec.from_string(&s, &i.type_)
-                        .map_err(|error| Error::CannotParseArg { arg: name, error })
                 } else if matches!(i.type_, ScSpecTypeDef::Option(_)) {
                     Ok(ScVal::Void)
                 } else if let Some(arg_path) =
                     matches_.get_one::<PathBuf>(&fmt_arg_file_name(&name))
                 {
-                    if matches!(i.type_, ScSpecTypeDef::Bytes | ScSpecTypeDef::BytesN(_)) {
-                        Ok(ScVal::try_from(
-                            &std::fs::read(arg_path)
-                                .map_err(|_| Error::MissingFileArg(arg_path.clone()))?,
-                        )
-                        .map_err(|()| Error::CannotParseArg {
-                            arg: name.clone(),
-                            error: soroban_spec_tools::Error::Unknown,
-                        })?)
-                    } else {
-                        let file_contents = std::fs::read_to_string(arg_path)
-                            .map_err(|_| Error::MissingFileArg(arg_path.clone()))?;
-                        tracing::debug!(
-                            "file {arg_path:?}, has contents:\n{file_contents}\nAnd type {:#?}\n{}",
-                            i.type_,
-                            file_contents.len()
-                        );
-                        spec.from_string(&file_contents, &i.type_)
-                            .map_err(|error| Error::CannotParseArg { arg: name, error })
-                    }
+                    parse_file_arg(&spec, &name, &i.type_, arg_path)
                 } else {
                     Err(Error::MissingArgument(name))
                 }
@@ -266,11 +361,20 @@ impl Cmd {
     }
 
     pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        if let Some(batch) = &self.batch {
+            return batch::run(self, batch, global_args).await;
+        }
         let res = self.invoke(global_args).await?.to_envelope();
         match res {
             TxnEnvelopeResult::TxnEnvelope(tx) => println!("{}", tx.to_xdr_base64(Limits::none())?),
             TxnEnvelopeResult::Res(output) => {
-                println!("{output}");
+                // Labels are for people reading the output, scripts get the plain result
+                if io::stdout().is_terminal() {
+                    let labels = self.config.locator.address_labels().unwrap_or_default();
+                    println!("{}", address::with_labels(&output, &labels));
+                } else {
+                    println!("{output}");
+                }
             }
         }
         Ok(())
@@ -280,6 +384,24 @@ impl Cmd {
         self.run_against_rpc_server(Some(global_args), None).await
     }
 
+    /// Read the JSON object of arguments passed with `--arg @FILE`, if any.
+    fn read_arg_file(&self) -> Result<serde_json::Map<String, serde_json::Value>, Error> {
+        if let Some(args) = &self.args {
+            return Ok(args.clone());
+        }
+        let Some(arg_file) = &self.arg_file else {
+            return Ok(serde_json::Map::new());
+        };
+        let path = PathBuf::from(arg_file.strip_prefix('@').unwrap_or(arg_file));
+        let contents =
+            fs::read_to_string(&path).map_err(|e| Error::CannotReadArgFile(path.clone(), e))?;
+        match serde_json::from_str(&contents) {
+            Ok(serde_json::Value::Object(args)) => Ok(args),
+            Ok(_) => Err(Error::ArgFileNotObject(path)),
+            Err(e) => Err(Error::CannotParseArgFile(path, e)),
+        }
+    }
+
     pub fn read_wasm(&self) -> Result<Option<Vec<u8>>, Error> {
         Ok(if let Some(wasm) = self.wasm.as_ref() {
             Some(fs::read(wasm).map_err(|e| Error::CannotReadContractFile(wasm.clone(), e))?)
@@ -307,45 +429,52 @@ impl NetworkRunnable for Cmd {
         global_args: Option<&global::Args>,
         config: Option<&config::Args>,
     ) -> Result<TxnResult<String>, Error> {
+        let print = global_args.map(Print::new).unwrap_or_default();
         let config = config.unwrap_or(&self.config);
         let network = config.get_network()?;
         tracing::trace!(?network);
+        let contract_id = self
+            .contract_id
+            .as_deref()
+            .ok_or(Error::MissingContractId)?;
         let contract_id = self
             .config
             .locator
-            .resolve_contract_id(&self.contract_id, &network.network_passphrase)?
+            .resolve_contract_id(contract_id, &network.network_passphrase)?
             .0;
         let spec_entries = self.spec_entries()?;
         if let Some(spec_entries) = &spec_entries {
             // For testing wasm arg parsing
             let _ = self.build_host_function_parameters(contract_id, spec_entries, config)?;
         }
-        let client = rpc::Client::new(&network.rpc_url)?;
+        let client = network.rpc_client(&print).await?;
         let account_details = if self.is_view {
             default_account_entry()
         } else {
             client
                 .verify_network_passphrase(Some(&network.network_passphrase))
                 .await?;
-            let key = config.key_pair()?;
+            let key = config.verifying_key()?;
 
             // Get the account sequence number
-            let public_strkey =
-                stellar_strkey::ed25519::PublicKey(key.verifying_key().to_bytes()).to_string();
-            client.get_account(&public_strkey).await?
+            let public_strkey = stellar_strkey::ed25519::PublicKey(key.to_bytes()).to_string();
+            client.retry(|| client.get_account(&public_strkey)).await?
         };
         let sequence: i64 = account_details.seq_num.into();
         let AccountId(PublicKey::PublicKeyTypeEd25519(account_id)) = account_details.account_id;
 
-        let spec_entries = get_remote_contract_spec(
-            &contract_id,
-            &config.locator,
-            &config.network,
-            global_args,
-            Some(config),
-        )
-        .await
-        .map_err(Error::from)?;
+        let spec_entries = match &self.spec {
+            Some(path) => read_spec_file(path)?,
+            None => get_remote_contract_spec(
+                &contract_id,
+                &config.locator,
+                &config.network,
+                global_args,
+                Some(config),
+            )
+            .await
+            .map_err(Error::from)?,
+        };
 
         // Get the ledger footprint
         let (function, spec, host_function_params, signers) =
@@ -354,21 +483,73 @@ impl NetworkRunnable for Cmd {
             host_function_params.clone(),
             sequence + 1,
             self.fee.fee,
-            account_id,
+            account_id.clone(),
         )?;
         if self.fee.build_only {
             return Ok(TxnResult::Txn(tx));
         }
-        let txn = client.simulate_and_assemble_transaction(&tx).await?;
-        let txn = self.fee.apply_to_assembled_txn(txn);
+        let txn = client
+            .retry(|| client.simulate_and_assemble_transaction(&tx))
+            .await?;
+        let mut txn = self.fee.apply_to_assembled_txn(txn);
         if self.fee.sim_only {
             return Ok(TxnResult::Txn(txn.transaction().clone()));
         }
+        let send = match self.send {
+            _ if self.is_view() => false,
+            ShouldSend::No => false,
+            ShouldSend::Yes => true,
+            ShouldSend::Default => {
+                let writes = writes_ledger(txn.transaction(), &txn.sim_response().events()?);
+                if !writes {
+                    print.infoln(
+                        "Simulation shows the invocation only reads from the ledger, so it was not sent. Pass --send=yes to send it",
+                    );
+                }
+                send_by_default(writes, self.confirmed(), io::stdin().is_terminal())?
+            }
+        };
+        if let Some(preamble) = txn.sim_response().restore_preamble.clone() {
+            if send {
+                if !self.restore {
+                    return Err(Error::ArchivedEntries);
+                }
+                print.infoln("Restoring archived ledger entries");
+                let restore = build_restore_footprint_tx(
+                    &preamble,
+                    sequence + 1,
+                    self.fee.fee,
+                    account_id.clone(),
+                )?;
+                review::review(
+                    &restore,
+                    &network.network_passphrase,
+                    &config.locator,
+                    self.confirmed(),
+                    &print,
+                )?;
+                client
+                    .send_transaction_polling(&config.sign_with_local_key(restore).await?)
+                    .await?;
+                // The restore used up the sequence number, so the invocation is built again
+                let tx = build_invoke_contract_tx(
+                    host_function_params.clone(),
+                    sequence + 2,
+                    self.fee.fee,
+                    account_id,
+                )?;
+                txn = self.fee.apply_to_assembled_txn(
+                    client
+                        .retry(|| client.simulate_and_assemble_transaction(&tx))
+                        .await?,
+                );
+            }
+        }
         let sim_res = txn.sim_response();
         if global_args.map_or(true, |a| !a.no_cache) {
-            data::write(sim_res.clone().into(), &network.rpc_uri()?)?;
+            data::write(sim_res.clone().into(), &client.uri()?)?;
         }
-        let (return_value, events) = if self.is_view() {
+        let (return_value, events) = if !send {
             // log_auth_cost_and_footprint(Some(&sim_res.transaction_data()?.resources));
             (sim_res.results()?[0].xdr.clone(), sim_res.events()?)
         } else {
@@ -378,15 +559,25 @@ impl NetworkRunnable for Cmd {
             // let auth = auth_entries(&txn);
             // crate::log::auth(&[auth]);
 
-            if let Some(tx) = config.sign_soroban_authorizations(&txn, &signers).await? {
+            if let Some(tx) = config
+                .sign_soroban_authorizations(&txn, &signers, &print)
+                .await?
+            {
                 txn = tx;
             }
             // log_auth_cost_and_footprint(resources(&txn));
+            review::review(
+                &txn,
+                &network.network_passphrase,
+                &config.locator,
+                self.confirmed(),
+                &print,
+            )?;
             let res = client
                 .send_transaction_polling(&config.sign_with_local_key(txn).await?)
                 .await?;
             if !no_cache {
-                data::write(res.clone().try_into()?, &network.rpc_uri()?)?;
+                data::write(res.clone().try_into()?, &client.uri()?)?;
             }
             (res.return_value()?, res.contract_events()?)
         };
@@ -396,6 +587,62 @@ impl NetworkRunnable for Cmd {
     }
 }
 
+/// Read spec entries written as a JSON array of base64 XDR entries or of JSON entries, as base64
+/// XDR, or as raw XDR.
+fn read_spec_file(path: &Path) -> Result<Vec<ScSpecEntry>, Error> {
+    let bytes = fs::read(path).map_err(|e| Error::CannotReadSpecFile(path.to_path_buf(), e))?;
+    parse_spec(&bytes).map_err(|e| Error::CannotParseSpecFile(path.to_path_buf(), e))
+}
+
+fn parse_spec(bytes: &[u8]) -> Result<Vec<ScSpecEntry>, String> {
+    let text = String::from_utf8_lossy(bytes);
+    let text = text.trim();
+    if text.starts_with('[') {
+        if let Ok(entries) = serde_json::from_str::<Vec<String>>(text) {
+            return entries
+                .iter()
+                .map(|entry| ScSpecEntry::from_xdr_base64(entry, Limits::none()))
+                .collect::<Result<_, _>>()
+                .map_err(|e| e.to_string());
+        }
+        return serde_json::from_str(text).map_err(|e| e.to_string());
+    }
+    let raw = base64::engine::general_purpose::STANDARD
+        .decode(text)
+        .unwrap_or_else(|_| bytes.to_vec());
+    ScSpecEntry::read_xdr_iter(&mut xdr::Limited::new(io::Cursor::new(raw), Limits::none()))
+        .collect::<Result<_, _>>()
+        .map_err(|e| e.to_string())
+}
+
+/// Whether `--send=default` sends the transaction. Only writes are sent, and they need to be
+/// confirmed, either with `--yes` or on the terminal when the transaction is reviewed.
+fn send_by_default(writes: bool, confirmed: bool, terminal: bool) -> Result<bool, Error> {
+    if writes && !confirmed && !terminal {
+        return Err(Error::SendNotConfirmed);
+    }
+    Ok(writes)
+}
+
+/// Whether sending the assembled transaction changes anything, going by its simulation: it writes
+/// to the ledger, needs authorization, or emits contract events.
+fn writes_ledger(tx: &Transaction, events: &[DiagnosticEvent]) -> bool {
+    let writes = matches!(
+        &tx.ext,
+        TransactionExt::V1(data) if !data.resources.footprint.read_write.is_empty()
+    );
+    let auth = tx.operations.iter().any(|op| {
+        matches!(
+            &op.body,
+            OperationBody::InvokeHostFunction(InvokeHostFunctionOp { auth, .. }) if !auth.is_empty()
+        )
+    });
+    let emits = events
+        .iter()
+        .any(|e| e.event.type_ == ContractEventType::Contract);
+    writes || auth || emits
+}
+
 const DEFAULT_ACCOUNT_ID: AccountId = AccountId(PublicKey::PublicKeyTypeEd25519(Uint256([0; 32])));
 
 // fn log_auth_cost_and_footprint(resources: Option<&SorobanResources>) {
@@ -459,6 +706,34 @@ pub fn output_to_string(
     Ok(TxnResult::Res(res_str))
 }
 
+/// Transaction restoring the archived entries that a simulation asked to restore.
+fn build_restore_footprint_tx(
+    preamble: &rpc::RestorePreamble,
+    sequence: i64,
+    fee: u32,
+    source_account_id: Uint256,
+) -> Result<Transaction, Error> {
+    let resource_fee = u32::try_from(preamble.min_resource_fee).unwrap_or(u32::MAX);
+    Ok(Transaction {
+        source_account: MuxedAccount::Ed25519(source_account_id),
+        fee: fee.saturating_add(resource_fee),
+        seq_num: SequenceNumber(sequence),
+        cond: Preconditions::None,
+        memo: Memo::None,
+        operations: vec![Operation {
+            source_account: None,
+            body: OperationBody::RestoreFootprint(RestoreFootprintOp {
+                ext: ExtensionPoint::V0,
+            }),
+        }]
+        .try_into()?,
+        ext: TransactionExt::V1(SorobanTransactionData::from_xdr_base64(
+            &preamble.transaction_data,
+            Limits::none(),
+        )?),
+    })
+}
+
 fn build_invoke_contract_tx(
     parameters: InvokeContractArgs,
     sequence: i64,
@@ -551,6 +826,68 @@ fn build_custom_cmd(name: &str, spec: &Spec) -> Result<clap::Command, Error> {
     Ok(cmd)
 }
 
+/// Parse an argument given as a string, resolving identities and address labels for address
+/// arguments and collecting the keys of identities so they can sign authorizations.
+fn parse_string_arg(
+    spec: &Spec,
+    name: String,
+    type_: &ScSpecTypeDef,
+    s: &str,
+    config: &config::Args,
+    signers: &mut Vec<SigningKey>,
+) -> Result<ScVal, Error> {
+    let mut s = s.to_string();
+    if matches!(type_, ScSpecTypeDef::Address) {
+        let cmd = crate::commands::keys::address::Cmd {
+            name: s.clone(),
+            hd_path: Some(0),
+            locator: config.locator.clone(),
+        };
+        if let Ok(address) = cmd.public_key() {
+            s = address.to_string();
+        } else if let Ok(address) = config.locator.read_address(&s) {
+            s = address;
+        }
+        if let Ok(key) = cmd.private_key() {
+            signers.push(key);
+        }
+    }
+    spec.from_string(&s, type_)
+        .map_err(|error| Error::CannotParseArg { arg: name, error })
+}
+
+/// Parse an argument from a file. Bytes and `BytesN` are read as raw bytes, every other type as
+/// its JSON representation.
+fn parse_file_arg(
+    spec: &Spec,
+    name: &str,
+    type_: &ScSpecTypeDef,
+    arg_path: &Path,
+) -> Result<ScVal, Error> {
+    if matches!(type_, ScSpecTypeDef::Bytes | ScSpecTypeDef::BytesN(_)) {
+        ScVal::try_from(
+            &std::fs::read(arg_path).map_err(|_| Error::MissingFileArg(arg_path.to_path_buf()))?,
+        )
+        .map_err(|()| Error::CannotParseArg {
+            arg: name.to_string(),
+            error: soroban_spec_tools::Error::Unknown,
+        })
+    } else {
+        let file_contents = std::fs::read_to_string(arg_path)
+            .map_err(|_| Error::MissingFileArg(arg_path.to_path_buf()))?;
+        tracing::debug!(
+            "file {arg_path:?}, has contents:\n{file_contents}\nAnd type {:#?}\n{}",
+            type_,
+            file_contents.len()
+        );
+        spec.from_string(&file_contents, type_)
+            .map_err(|error| Error::CannotParseArg {
+                arg: name.to_string(),
+                error,
+            })
+    }
+}
+
 fn fmt_arg_file_name(name: &str) -> String {
     format!("{name}-file-path")
 }
@@ -560,6 +897,119 @@ fn arg_file_help(docs: &str) -> String {
         r#"{docs}
 Usage Notes:
 Each arg has a corresponding --<arg_name>-file-path which is a path to a file containing the corresponding JSON argument.
+A value of the form @<path> is also read from that file, e.g. --arg-name @value.json; use @@ for a literal leading @.
 Note: The only types which aren't JSON are Bytes and BytesN, which are raw bytes"#
     )
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn spec() -> Vec<ScSpecEntry> {
+        ["hello", "world"]
+            .map(|name| {
+                ScSpecEntry::FunctionV0(ScSpecFunctionV0 {
+                    doc: StringM::default(),
+                    name: name.try_into().unwrap(),
+                    inputs: VecM::default(),
+                    outputs: VecM::default(),
+                })
+            })
+            .to_vec()
+    }
+
+    fn raw_xdr(entries: &[ScSpecEntry]) -> Vec<u8> {
+        entries
+            .iter()
+            .flat_map(|entry| entry.to_xdr(Limits::none()).unwrap())
+            .collect()
+    }
+
+    #[test]
+    fn parse_spec_formats() {
+        let entries = spec();
+        let base64_array = serde_json::to_string(
+            &entries
+                .iter()
+                .map(|entry| entry.to_xdr_base64(Limits::none()).unwrap())
+                .collect::<Vec<_>>(),
+        )
+        .unwrap();
+        let json = serde_json::to_string(&entries).unwrap();
+        let raw = raw_xdr(&entries);
+        let base64 = base64::engine::general_purpose::STANDARD.encode(&raw);
+
+        assert_eq!(parse_spec(base64_array.as_bytes()).unwrap(), entries);
+        assert_eq!(parse_spec(json.as_bytes()).unwrap(), entries);
+        assert_eq!(
+            parse_spec(format!("{base64}\n").as_bytes()).unwrap(),
+            entries
+        );
+        assert_eq!(parse_spec(&raw).unwrap(), entries);
+    }
+
+    #[test]
+    fn parse_spec_rejects_malformed_files() {
+        assert!(parse_spec(b"[\"not xdr\"]").is_err());
+        assert!(parse_spec(b"[{\"function_v0\": 1}]").is_err());
+        assert!(parse_spec(&raw_xdr(&spec())[..10]).is_err());
+    }
+
+    #[test]
+    fn default_send_needs_confirmation_without_terminal() {
+        assert!(!send_by_default(false, false, false).unwrap());
+        assert!(send_by_default(true, false, true).unwrap());
+        assert!(send_by_default(true, true, false).unwrap());
+        assert!(matches!(
+            send_by_default(true, false, false),
+            Err(Error::SendNotConfirmed)
+        ));
+    }
+
+    #[test]
+    fn auth_and_events_count_as_writes() {
+        let args = InvokeContractArgs {
+            contract_address: ScAddress::Contract(Hash([0; 32])),
+            function_name: "hello".try_into().unwrap(),
+            args: VecM::default(),
+        };
+        let tx = build_invoke_contract_tx(args.clone(), 1, 100, Uint256([0; 32])).unwrap();
+        assert!(!writes_ledger(&tx, &[]));
+
+        let event = DiagnosticEvent {
+            in_successful_contract_call: true,
+            event: xdr::ContractEvent {
+                ext: ExtensionPoint::V0,
+                contract_id: None,
+                type_: ContractEventType::Contract,
+                body: xdr::ContractEventBody::V0(xdr::ContractEventV0 {
+                    topics: VecM::default(),
+                    data: ScVal::Void,
+                }),
+            },
+        };
+        assert!(writes_ledger(&tx, &[event]));
+
+        let auth = xdr::SorobanAuthorizationEntry {
+            credentials: xdr::SorobanCredentials::SourceAccount,
+            root_invocation: xdr::SorobanAuthorizedInvocation {
+                function: xdr::SorobanAuthorizedFunction::ContractFn(args.clone()),
+                sub_invocations: VecM::default(),
+            },
+        };
+        let tx = Transaction {
+            operations: vec![Operation {
+                source_account: None,
+                body: OperationBody::InvokeHostFunction(InvokeHostFunctionOp {
+                    host_function: HostFunction::InvokeContract(args),
+                    auth: vec![auth].try_into().unwrap(),
+                }),
+            }]
+            .try_into()
+            .unwrap(),
+            ..tx
+        };
+        assert!(writes_ledger(&tx, &[]));
+    }
+}
@@ -0,0 +1,281 @@
+use std::{
+    collections::{BTreeMap, HashSet},
+    fs::{self, OpenOptions},
+    io::{self, Write},
+    path::{Path, PathBuf},
+    sync::Mutex,
+};
+
+use futures_util::{stream, StreamExt, TryStreamExt};
+use serde::{Deserialize, Serialize};
+use serde_json::{Map, Value};
+
+use crate::{
+    commands::{global, txn_result::TxnEnvelopeResult},
+    print::Print,
+    xdr::{Limits, WriteXdr},
+};
+
+use super::{Cmd, ShouldSend};
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error("reading batch file {0:?}: {1}")]
+    CannotReadBatch(PathBuf, io::Error),
+    #[error("parsing batch file {0:?}: {1}")]
+    CannotParseJson(PathBuf, serde_json::Error),
+    #[error("parsing batch file {0:?}: {1}")]
+    CannotParseCsv(PathBuf, csv::Error),
+    #[error("job {0} in the batch file has no function")]
+    MissingFunction(usize),
+    #[error("reading batch results {0:?}: {1}")]
+    CannotReadResults(PathBuf, io::Error),
+    #[error("writing batch results {0:?}: {1}")]
+    CannotWriteResults(PathBuf, io::Error),
+    #[error("running batch jobs at once needs --yes or --send, so their confirmation prompts don't compete for the terminal")]
+    ConcurrentReview,
+    #[error("{failed} of {total} jobs failed, see {results:?}")]
+    JobsFailed {
+        failed: usize,
+        total: usize,
+        results: PathBuf,
+    },
+}
+
+/// One invocation of a batch.
+///
+/// A JSON batch file is an array of these objects. A CSV batch file has a header row naming the
+/// `id`, `source` and `fn` columns, and every other column is an argument of the function.
+/// Arguments are written the way they would be on the command line, e.g. `"amount": "100"`.
+#[derive(Deserialize, Debug, Clone, Default)]
+pub struct Job {
+    /// Contract to invoke, defaults to `--id`
+    pub id: Option<String>,
+    /// Account that signs the job's transaction, defaults to `--source-account`
+    pub source: Option<String>,
+    /// Function to invoke
+    #[serde(rename = "fn")]
+    pub function: String,
+    /// Arguments of the function by name
+    #[serde(default)]
+    pub args: Map<String, Value>,
+}
+
+/// A line of the results file. Jobs are identified by their position in the batch file, from 0.
+#[derive(Serialize, Deserialize, Debug)]
+struct JobResult {
+    job: usize,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    result: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    error: Option<String>,
+}
+
+pub async fn run(cmd: &Cmd, batch: &Path, global_args: &global::Args) -> Result<(), super::Error> {
+    let print = Print::new(global_args);
+    if cmd.concurrency > 1 && !cmd.confirmed() && cmd.send != ShouldSend::No {
+        return Err(Error::ConcurrentReview.into());
+    }
+    let jobs = read_jobs(batch)?;
+    let results_path = cmd
+        .batch_results
+        .clone()
+        .unwrap_or_else(|| batch.with_extension("results.jsonl"));
+    let succeeded = succeeded_jobs(&results_path)?;
+    if !succeeded.is_empty() {
+        print.infoln(format!(
+            "Skipping {} jobs that already succeeded according to {}",
+            succeeded.len(),
+            results_path.display()
+        ));
+    }
+    let results = OpenOptions::new()
+        .create(true)
+        .append(true)
+        .open(&results_path)
+        .map_err(|e| Error::CannotWriteResults(results_path.clone(), e))?;
+    let results = Mutex::new(results);
+
+    // Jobs sharing a source account run in order, so their sequence numbers don't collide. The
+    // account is keyed by its public key, so an alias and its address share a queue
+    let mut queues: BTreeMap<[u8; 32], Vec<(usize, Cmd)>> = BTreeMap::new();
+    for (index, job) in jobs.into_iter().enumerate() {
+        if !succeeded.contains(&index) {
+            let job_cmd = job_cmd(cmd, job);
+            let source = job_cmd.config.verifying_key()?.to_bytes();
+            queues.entry(source).or_default().push((index, job_cmd));
+        }
+    }
+    let total = queues.values().map(Vec::len).sum::<usize>();
+    if total == 0 {
+        print.checkln("All jobs have already succeeded");
+        return Ok(());
+    }
+
+    let failed = stream::iter(queues.into_values())
+        .map(|queue| run_queue(queue, global_args, &print, &results, &results_path))
+        .buffer_unordered(cmd.concurrency.max(1))
+        .try_fold(0, |total, failed| async move { Ok(total + failed) })
+        .await?;
+    if failed > 0 {
+        return Err(Error::JobsFailed {
+            failed,
+            total,
+            results: results_path,
+        }
+        .into());
+    }
+    print.checkln(format!("All {total} jobs succeeded"));
+    Ok(())
+}
+
+/// Run the jobs of one source account one after another, returning how many failed.
+async fn run_queue(
+    queue: Vec<(usize, Cmd)>,
+    global_args: &global::Args,
+    print: &Print,
+    results: &Mutex<fs::File>,
+    results_path: &Path,
+) -> Result<usize, super::Error> {
+    let mut failed = 0;
+    for (index, job_cmd) in queue {
+        let result = match job_cmd.invoke(global_args).await {
+            Ok(res) => {
+                print.checkln(format!("Job {index} succeeded"));
+                let result = match res.to_envelope() {
+                    TxnEnvelopeResult::TxnEnvelope(tx) => tx.to_xdr_base64(Limits::none())?,
+                    TxnEnvelopeResult::Res(output) => output,
+                };
+                JobResult {
+                    job: index,
+                    result: Some(result),
+                    error: None,
+                }
+            }
+            Err(e) => {
+                failed += 1;
+                print.errorln(format!("Job {index} failed: {e}"));
+                JobResult {
+                    job: index,
+                    result: None,
+                    error: Some(e.to_string()),
+                }
+            }
+        };
+        let mut file = results.lock().unwrap();
+        serde_json::to_writer(&mut *file, &result)
+            .map_err(io::Error::from)
+            .and_then(|()| writeln!(file))
+            .map_err(|e| Error::CannotWriteResults(results_path.to_path_buf(), e))?;
+    }
+    Ok(failed)
+}
+
+/// The invoke command for a single job, with the batch's flags as defaults.
+fn job_cmd(cmd: &Cmd, job: Job) -> Cmd {
+    let mut job_cmd = cmd.clone();
+    job_cmd.batch = None;
+    if job.id.is_some() {
+        job_cmd.contract_id = job.id;
+    }
+    if let Some(source) = job.source {
+        job_cmd.config.source_account = source;
+    }
+    job_cmd.slop = vec![job.function.into()];
+    job_cmd.args = Some(job.args);
+    job_cmd
+}
+
+fn read_jobs(path: &Path) -> Result<Vec<Job>, Error> {
+    let is_csv = path
+        .extension()
+        .is_some_and(|extension| extension.eq_ignore_ascii_case("csv"));
+    let jobs: Vec<Job> = if is_csv {
+        let csv_err = |e| Error::CannotParseCsv(path.to_path_buf(), e);
+        let mut reader = csv::Reader::from_path(path).map_err(csv_err)?;
+        let headers = reader.headers().map_err(csv_err)?.clone();
+        reader
+            .records()
+            .map(|record| Ok(job_from_record(&headers, &record?)))
+            .collect::<Result<_, csv::Error>>()
+            .map_err(csv_err)?
+    } else {
+        let contents =
+            fs::read_to_string(path).map_err(|e| Error::CannotReadBatch(path.to_path_buf(), e))?;
+        serde_json::from_str(&contents)
+            .map_err(|e| Error::CannotParseJson(path.to_path_buf(), e))?
+    };
+    if let Some(index) = jobs.iter().position(|job| job.function.is_empty()) {
+        return Err(Error::MissingFunction(index));
+    }
+    Ok(jobs)
+}
+
+/// A job from a CSV row. Empty cells are left out, so optional arguments can be skipped per row.
+fn job_from_record(headers: &csv::StringRecord, record: &csv::StringRecord) -> Job {
+    let mut job = Job::default();
+    for (header, value) in headers.iter().zip(record) {
+        if value.is_empty() {
+            continue;
+        }
+        match header {
+            "id" => job.id = Some(value.to_string()),
+            "source" => job.source = Some(value.to_string()),
+            "fn" => job.function = value.to_string(),
+            _ => {
+                job.args
+                    .insert(header.to_string(), Value::String(value.to_string()));
+            }
+        }
+    }
+    job
+}
+
+/// Jobs recorded as succeeded in the results file of a previous run.
+fn succeeded_jobs(path: &Path) -> Result<HashSet<usize>, Error> {
+    let contents = match fs::read_to_string(path) {
+        Ok(contents) => contents,
+        Err(e) if e.kind() == io::ErrorKind::NotFound => return Ok(HashSet::new()),
+        Err(e) => return Err(Error::CannotReadResults(path.to_path_buf(), e)),
+    };
+    // A line cut short by an interrupted run doesn't parse, and its job is run again
+    Ok(contents
+        .lines()
+        .filter_map(|line| serde_json::from_str::<JobResult>(line).ok())
+        .filter(|result| result.error.is_none())
+        .map(|result| result.job)
+        .collect())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn csv_columns_become_arguments() {
+        let headers = csv::StringRecord::from(vec!["fn", "source", "to", "amount", "memo"]);
+        let record = csv::StringRecord::from(vec!["transfer", "alice", "bob", "100", ""]);
+        let job = job_from_record(&headers, &record);
+        assert_eq!(job.function, "transfer");
+        assert_eq!(job.source.as_deref(), Some("alice"));
+        assert_eq!(job.id, None);
+        assert_eq!(job.args.len(), 2);
+        assert_eq!(job.args["to"], "bob");
+        assert_eq!(job.args["amount"], "100");
+    }
+
+    #[test]
+    fn only_succeeded_jobs_are_skipped() {
+        let dir = tempfile::tempdir().unwrap();
+        let path = dir.path().join("jobs.results.jsonl");
+        fs::write(
+            &path,
+            "{\"job\":0,\"result\":\"null\"}\n{\"job\":1,\"error\":\"boom\"}\n{\"job\":2,\"res",
+        )
+        .unwrap();
+        assert_eq!(succeeded_jobs(&path).unwrap(), HashSet::from([0]));
+        assert!(succeeded_jobs(&dir.path().join("missing.jsonl"))
+            .unwrap()
+            .is_empty());
+    }
+}
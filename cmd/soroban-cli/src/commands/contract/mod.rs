@@ -2,6 +2,8 @@ pub mod asset;
 pub mod bindings;
 pub mod build;
 pub mod deploy;
+pub mod doc;
+pub mod estimate;
 pub mod extend;
 pub mod fetch;
 pub mod id;
@@ -12,6 +14,8 @@ pub mod invoke;
 pub mod optimize;
 pub mod read;
 pub mod restore;
+pub mod size;
+pub mod snapshot;
 
 use crate::commands::global;
 
@@ -34,6 +38,10 @@ pub enum Cmd {
     /// Deploy a wasm contract
     Deploy(deploy::wasm::Cmd),
 
+    Doc(doc::Cmd),
+
+    Estimate(estimate::Cmd),
+
     /// Fetch a contract's Wasm binary
     Fetch(fetch::Cmd),
 
@@ -70,6 +78,10 @@ pub enum Cmd {
     ///
     /// If no keys are specificed the contract itself is restored.
     Restore(restore::Cmd),
+
+    Size(size::Cmd),
+
+    Snapshot(snapshot::Cmd),
 }
 
 #[derive(thiserror::Error, Debug)]
@@ -89,6 +101,12 @@ pub enum Error {
     #[error(transparent)]
     Deploy(#[from] deploy::wasm::Error),
 
+    #[error(transparent)]
+    Doc(#[from] doc::Error),
+
+    #[error(transparent)]
+    Estimate(#[from] estimate::Error),
+
     #[error(transparent)]
     Fetch(#[from] fetch::Error),
 
@@ -115,25 +133,35 @@ pub enum Error {
 
     #[error(transparent)]
     Restore(#[from] restore::Error),
+
+    #[error(transparent)]
+    Size(#[from] size::Error),
+
+    #[error(transparent)]
+    Snapshot(#[from] snapshot::Error),
 }
 
 impl Cmd {
     pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
         match &self {
-            Cmd::Asset(asset) => asset.run().await?,
-            Cmd::Bindings(bindings) => bindings.run().await?,
-            Cmd::Build(build) => build.run()?,
-            Cmd::Extend(extend) => extend.run().await?,
-            Cmd::Deploy(deploy) => deploy.run().await?,
+            Cmd::Asset(asset) => asset.run(global_args).await?,
+            Cmd::Bindings(bindings) => bindings.run(global_args).await?,
+            Cmd::Build(build) => build.run(global_args)?,
+            Cmd::Extend(extend) => extend.run(global_args).await?,
+            Cmd::Deploy(deploy) => deploy.run(global_args).await?,
+            Cmd::Doc(doc) => doc.run(global_args)?,
+            Cmd::Estimate(estimate) => estimate.run(global_args).await?,
             Cmd::Id(id) => id.run()?,
-            Cmd::Init(init) => init.run()?,
+            Cmd::Init(init) => init.run(global_args)?,
             Cmd::Inspect(inspect) => inspect.run()?,
-            Cmd::Install(install) => install.run().await?,
+            Cmd::Install(install) => install.run(global_args).await?,
             Cmd::Invoke(invoke) => invoke.run(global_args).await?,
-            Cmd::Optimize(optimize) => optimize.run()?,
-            Cmd::Fetch(fetch) => fetch.run().await?,
-            Cmd::Read(read) => read.run().await?,
-            Cmd::Restore(restore) => restore.run().await?,
+            Cmd::Optimize(optimize) => optimize.run(global_args)?,
+            Cmd::Fetch(fetch) => fetch.run(global_args).await?,
+            Cmd::Read(read) => read.run(global_args).await?,
+            Cmd::Restore(restore) => restore.run(global_args).await?,
+            Cmd::Size(size) => size.run(global_args).await?,
+            Cmd::Snapshot(snapshot) => snapshot.run(global_args).await?,
         }
         Ok(())
     }
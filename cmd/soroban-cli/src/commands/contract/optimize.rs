@@ -3,7 +3,7 @@ use std::fmt::Debug;
 #[cfg(feature = "opt")]
 use wasm_opt::{Feature, OptimizationError, OptimizationOptions};
 
-use crate::wasm;
+use crate::{commands::global, print::Print, wasm};
 
 #[derive(Parser, Debug, Clone)]
 #[group(skip)]
@@ -29,19 +29,20 @@ pub enum Error {
 
 impl Cmd {
     #[cfg(not(feature = "opt"))]
-    pub fn run(&self) -> Result<(), Error> {
+    pub fn run(&self, _global_args: &global::Args) -> Result<(), Error> {
         Err(Error::Install)
     }
 
     #[cfg(feature = "opt")]
-    pub fn run(&self) -> Result<(), Error> {
+    pub fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
         let wasm_size = self.wasm.len()?;
 
-        println!(
+        print.infoln(format!(
             "Reading: {} ({} bytes)",
             self.wasm.wasm.to_string_lossy(),
             wasm_size
-        );
+        ));
 
         let wasm_out = self.wasm_out.as_ref().cloned().unwrap_or_else(|| {
             let mut wasm_out = self.wasm.wasm.clone();
@@ -68,11 +69,11 @@ impl Cmd {
             .map_err(Error::OptimizationError)?;
 
         let wasm_out_size = wasm::len(&wasm_out)?;
-        println!(
+        print.checkln(format!(
             "Optimized: {} ({} bytes)",
             wasm_out.to_string_lossy(),
             wasm_out_size
-        );
+        ));
 
         Ok(())
     }
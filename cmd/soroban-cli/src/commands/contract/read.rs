@@ -15,10 +15,11 @@ use soroban_env_host::{
 use crate::{
     commands::{
         config::{self, locator},
-        global, NetworkRunnable,
+        global, network, NetworkRunnable,
     },
     key,
-    rpc::{self, Client, FullLedgerEntries, FullLedgerEntry},
+    print::Print,
+    rpc::{self, FullLedgerEntries, FullLedgerEntry},
 };
 
 #[derive(Parser, Debug, Clone)]
@@ -78,6 +79,8 @@ pub enum Error {
     #[error(transparent)]
     Rpc(#[from] rpc::Error),
     #[error(transparent)]
+    Network(#[from] network::Error),
+    #[error(transparent)]
     Xdr(#[from] XdrError),
     #[error(transparent)]
     // TODO: the Display impl of host errors is pretty user-unfriendly
@@ -94,8 +97,8 @@ pub enum Error {
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
-        let entries = self.run_against_rpc_server(None, None).await?;
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let entries = self.run_against_rpc_server(Some(global_args), None).await?;
         self.output_entries(&entries)
     }
 
@@ -181,18 +184,21 @@ impl NetworkRunnable for Cmd {
 
     async fn run_against_rpc_server(
         &self,
-        _: Option<&global::Args>,
+        args: Option<&global::Args>,
         config: Option<&config::Args>,
     ) -> Result<FullLedgerEntries, Error> {
+        let print = args.map(Print::new).unwrap_or_default();
         let config = config.unwrap_or(&self.config);
         let network = config.get_network()?;
         tracing::trace!(?network);
-        let client = Client::new(&network.rpc_url)?;
+        let client = network.rpc_client(&print).await?;
         let contract = config.locator.resolve_contract_id(
             self.key.contract_id.as_ref().unwrap(),
             &network.network_passphrase,
         )?;
         let keys = self.key.parse_keys(contract)?;
-        Ok(client.get_full_ledger_entries(&keys).await?)
+        Ok(client
+            .retry(|| client.get_full_ledger_entries(&keys))
+            .await?)
     }
 }
@@ -18,8 +18,8 @@ use crate::{
         NetworkRunnable,
     },
     key,
-    rpc::{self, Client},
-    wasm, Pwd,
+    print::Print,
+    review, rpc, wasm, Pwd,
 };
 
 #[derive(Parser, Debug, Clone)]
@@ -89,12 +89,17 @@ pub enum Error {
     Data(#[from] data::Error),
     #[error(transparent)]
     Network(#[from] network::Error),
+    #[error(transparent)]
+    Review(#[from] review::Error),
 }
 
 impl Cmd {
     #[allow(clippy::too_many_lines)]
-    pub async fn run(&self) -> Result<(), Error> {
-        let res = self.run_against_rpc_server(None, None).await?.to_envelope();
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let res = self
+            .run_against_rpc_server(Some(global_args), None)
+            .await?
+            .to_envelope();
         let expiration_ledger_seq = match res {
             TxnEnvelopeResult::TxnEnvelope(tx) => {
                 println!("{}", tx.to_xdr_base64(Limits::none())?);
@@ -110,7 +115,7 @@ impl Cmd {
                 fee: self.fee.clone(),
                 ttl_ledger_only: false,
             }
-            .run()
+            .run(global_args)
             .await?;
         } else {
             println!("New ttl ledger: {expiration_ledger_seq}");
@@ -130,6 +135,7 @@ impl NetworkRunnable for Cmd {
         args: Option<&global::Args>,
         config: Option<&config::Args>,
     ) -> Result<TxnResult<u32>, Error> {
+        let print = args.map(Print::new).unwrap_or_default();
         let config = config.unwrap_or(&self.config);
         let network = config.get_network()?;
         tracing::trace!(?network);
@@ -138,17 +144,16 @@ impl NetworkRunnable for Cmd {
             &network.network_passphrase,
         )?;
         let entry_keys = self.key.parse_keys(contract)?;
-        let client = Client::new(&network.rpc_url)?;
-        let key = config.key_pair()?;
+        let client = network.rpc_client(&print).await?;
+        let key = config.verifying_key()?;
 
         // Get the account sequence number
-        let public_strkey =
-            stellar_strkey::ed25519::PublicKey(key.verifying_key().to_bytes()).to_string();
-        let account_details = client.get_account(&public_strkey).await?;
+        let public_strkey = stellar_strkey::ed25519::PublicKey(key.to_bytes()).to_string();
+        let account_details = client.retry(|| client.get_account(&public_strkey)).await?;
         let sequence: i64 = account_details.seq_num.into();
 
         let tx = Transaction {
-            source_account: MuxedAccount::Ed25519(Uint256(key.verifying_key().to_bytes())),
+            source_account: MuxedAccount::Ed25519(Uint256(key.to_bytes())),
             fee: self.fee.fee,
             seq_num: SequenceNumber(sequence + 1),
             cond: Preconditions::None,
@@ -177,11 +182,18 @@ impl NetworkRunnable for Cmd {
         if self.fee.build_only {
             return Ok(TxnResult::Txn(tx));
         }
+        review::review(
+            &tx,
+            &network.network_passphrase,
+            &config.locator,
+            self.fee.yes,
+            &print,
+        )?;
         let res = client
             .send_transaction_polling(&config.sign_with_local_key(tx).await?)
             .await?;
         if args.map_or(true, |a| !a.no_cache) {
-            data::write(res.clone().try_into()?, &network.rpc_uri()?)?;
+            data::write(res.clone().try_into()?, &client.uri()?)?;
         }
         let meta = res
             .result_meta
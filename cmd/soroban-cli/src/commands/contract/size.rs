@@ -0,0 +1,184 @@
+use std::ops::Range;
+
+use clap::{arg, command, Parser};
+use wasmparser::{BinaryReaderError, Payload};
+
+use crate::{
+    commands::{config::locator, global, network},
+    print::Print,
+    rpc, wasm,
+    xdr::{
+        self, ConfigSettingEntry, ConfigSettingId, LedgerEntryData, LedgerKey,
+        LedgerKeyConfigSetting, Limits, ReadXdr,
+    },
+};
+
+/// Largest contract the Stellar networks accept, used when no network is given
+pub const DEFAULT_MAX_SIZE: u64 = 65_536;
+
+/// Print the size of each section of a wasm file, and fail if the file is larger than the network
+/// accepts
+#[derive(Parser, Debug, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    #[command(flatten)]
+    pub wasm: wasm::Args,
+    /// Largest wasm allowed, in bytes. Defaults to the maximum contract size of --network, or 65536
+    #[arg(long, conflicts_with = "network")]
+    pub max_size: Option<u64>,
+    /// Name of the network to read the maximum contract size from
+    #[arg(long)]
+    pub network: Option<String>,
+    #[command(flatten)]
+    pub locator: locator::Args,
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Wasm(#[from] wasm::Error),
+    #[error("parsing wasm file {0:?}: {1}")]
+    CannotParseWasm(std::path::PathBuf, BinaryReaderError),
+    #[error(transparent)]
+    Locator(#[from] locator::Error),
+    #[error(transparent)]
+    Rpc(#[from] rpc::Error),
+    #[error(transparent)]
+    Network(#[from] network::Error),
+    #[error(transparent)]
+    Xdr(#[from] xdr::Error),
+    #[error("network did not return its maximum contract size")]
+    MissingMaxSize,
+    #[error("wasm is {size} bytes, larger than the limit of {max} bytes")]
+    OverBudget { size: u64, max: u64 },
+}
+
+/// Size of the contents of a wasm section, without its id and length.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Section {
+    pub name: String,
+    pub size: usize,
+}
+
+impl Cmd {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
+        let bytes = self.wasm.read()?;
+        let sections =
+            sections(&bytes).map_err(|e| Error::CannotParseWasm(self.wasm.wasm.clone(), e))?;
+        let max = self.max_size(&print).await?;
+
+        let size = bytes.len() as u64;
+        let width = sections
+            .iter()
+            .map(|s| s.name.len())
+            .fold("total".len(), usize::max);
+        for section in &sections {
+            println!("{:width$}  {:>8}", section.name, section.size);
+        }
+        println!("{:width$}  {size:>8}", "total");
+
+        if size > max {
+            return Err(Error::OverBudget { size, max });
+        }
+        print.checkln(format!(
+            "{size} of {max} bytes ({:.1}%)",
+            size as f64 * 100.0 / max as f64
+        ));
+        Ok(())
+    }
+
+    async fn max_size(&self, print: &Print) -> Result<u64, Error> {
+        if let Some(max) = self.max_size {
+            return Ok(max);
+        }
+        let Some(name) = &self.network else {
+            return Ok(DEFAULT_MAX_SIZE);
+        };
+        let network = self.locator.read_network(name)?;
+        let key = LedgerKey::ConfigSetting(LedgerKeyConfigSetting {
+            config_setting_id: ConfigSettingId::ContractMaxSizeBytes,
+        });
+        let keys = [key];
+        let client = network.rpc_client(print).await?;
+        let entries = client
+            .retry(|| client.get_ledger_entries(&keys))
+            .await?
+            .entries
+            .unwrap_or_default();
+        let entry = entries.first().ok_or(Error::MissingMaxSize)?;
+        match LedgerEntryData::from_xdr_base64(&entry.xdr, Limits::none())? {
+            LedgerEntryData::ConfigSetting(ConfigSettingEntry::ContractMaxSizeBytes(max)) => {
+                Ok(max.into())
+            }
+            _ => Err(Error::MissingMaxSize),
+        }
+    }
+}
+
+/// Sections of the wasm in the order they appear, with custom sections named after their name,
+/// e.g. `custom contractspecv0`.
+pub fn sections(bytes: &[u8]) -> Result<Vec<Section>, BinaryReaderError> {
+    let mut sections = vec![];
+    for payload in wasmparser::Parser::new(0).parse_all(bytes) {
+        let payload = payload?;
+        let Some((id, Range { start, end })) = payload.as_section() else {
+            continue;
+        };
+        let name = match &payload {
+            Payload::CustomSection(section) => format!("custom {}", section.name()),
+            _ => section_name(id).to_string(),
+        };
+        sections.push(Section {
+            name,
+            size: end - start,
+        });
+    }
+    Ok(sections)
+}
+
+fn section_name(id: u8) -> &'static str {
+    match id {
+        1 => "type",
+        2 => "import",
+        3 => "function",
+        4 => "table",
+        5 => "memory",
+        6 => "global",
+        7 => "export",
+        8 => "start",
+        9 => "element",
+        10 => "code",
+        11 => "data",
+        12 => "datacount",
+        _ => "unknown",
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn lists_sections() {
+        // Empty module with a memory section and a custom section named "x" holding one byte
+        let wasm = [
+            0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, // header
+            0x05, 0x03, 0x01, 0x00, 0x01, // memory
+            0x00, 0x03, 0x01, b'x', 0x2a, // custom
+        ];
+        assert_eq!(
+            sections(&wasm).unwrap(),
+            vec![
+                Section {
+                    name: "memory".to_string(),
+                    size: 3
+                },
+                Section {
+                    name: "custom x".to_string(),
+                    size: 3
+                },
+            ]
+        );
+    }
+}
@@ -0,0 +1,184 @@
+use std::path::PathBuf;
+
+use clap::{arg, command, Parser};
+use sha2::{Digest, Sha256};
+use soroban_env_host::xdr::{
+    ContractDataDurability, ContractDataEntry, ContractExecutable, Hash, LedgerEntry,
+    LedgerEntryData, LedgerEntryExt, LedgerKey, LedgerKeyContractCode, LedgerKeyContractData,
+    ScAddress, ScContractInstance, ScVal,
+};
+use soroban_ledger_snapshot::LedgerSnapshot;
+use stellar_strkey::Contract;
+
+use crate::{
+    commands::{config::locator, contract::Durability, global, network},
+    key,
+    print::Print,
+    rpc::{self, FullLedgerEntry},
+};
+
+// Keys accepted by a single `getLedgerEntries` request
+const LEDGER_ENTRIES_LIMIT: usize = 200;
+
+/// Save a deployed contract's code, instance, and data entries to a ledger snapshot file
+///
+/// The entries are fetched from RPC and added to the snapshot file, replacing older copies of the
+/// same entries, so several contracts can be collected in one file. Load the file in tests with
+/// `Env::from_ledger_snapshot_file` to reproduce the network's state locally for debugging.
+///
+/// RPC cannot list the data entries of a contract, so only the entries whose keys are given with
+/// `--key` or `--key-xdr` are saved along with the contract's instance and code.
+#[derive(Parser, Debug, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// Contract ID to snapshot
+    #[arg(long = "id", env = "STELLAR_CONTRACT_ID")]
+    pub contract_id: String,
+    /// Storage key of a data entry to save (symbols only)
+    #[arg(long = "key", conflicts_with = "key_xdr")]
+    pub key: Option<Vec<String>>,
+    /// Storage key of a data entry to save (base64-encoded XDR)
+    #[arg(long = "key-xdr", conflicts_with = "key")]
+    pub key_xdr: Option<Vec<String>>,
+    /// Durability of the data entries given with --key or --key-xdr
+    #[arg(long, value_enum, default_value = "persistent")]
+    pub durability: Durability,
+    /// Snapshot file to add the entries to, created if it does not exist
+    #[arg(long, short = 'o', default_value = "snapshot.json")]
+    pub out: PathBuf,
+    #[command(flatten)]
+    pub locator: locator::Args,
+    #[command(flatten)]
+    pub network: network::Args,
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Rpc(#[from] rpc::Error),
+    #[error(transparent)]
+    Locator(#[from] locator::Error),
+    #[error(transparent)]
+    Network(#[from] network::Error),
+    #[error(transparent)]
+    Key(#[from] key::Error),
+    #[error("contract {0} not found")]
+    ContractNotFound(String),
+    #[error("reading snapshot file {0:?}: {1}")]
+    CannotReadSnapshot(PathBuf, soroban_ledger_snapshot::Error),
+    #[error("writing snapshot file {0:?}: {1}")]
+    CannotWriteSnapshot(PathBuf, soroban_ledger_snapshot::Error),
+}
+
+impl Cmd {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
+        let network = self.network.get(&self.locator)?;
+        let contract = self
+            .locator
+            .resolve_contract_id(&self.contract_id, &network.network_passphrase)?;
+        let client = network.rpc_client(&print).await?;
+        client
+            .verify_network_passphrase(Some(&network.network_passphrase))
+            .await?;
+
+        let instance_key = LedgerKey::ContractData(LedgerKeyContractData {
+            contract: ScAddress::Contract(Hash(contract.0)),
+            key: ScVal::LedgerKeyContractInstance,
+            durability: ContractDataDurability::Persistent,
+        });
+        let mut keys = vec![instance_key.clone()];
+        for key in self.data_keys(contract)? {
+            if !keys.contains(&key) {
+                keys.push(key);
+            }
+        }
+        let mut entries = Vec::new();
+        for keys in keys.chunks(LEDGER_ENTRIES_LIMIT) {
+            entries.extend(
+                client
+                    .retry(|| client.get_full_ledger_entries(keys))
+                    .await?
+                    .entries,
+            );
+        }
+        let Some(instance) = entries.iter().find(|entry| entry.key == instance_key) else {
+            return Err(Error::ContractNotFound(contract.to_string()));
+        };
+        if let LedgerEntryData::ContractData(ContractDataEntry {
+            val:
+                ScVal::ContractInstance(ScContractInstance {
+                    executable: ContractExecutable::Wasm(hash),
+                    ..
+                }),
+            ..
+        }) = &instance.val
+        {
+            let code_keys = [LedgerKey::ContractCode(LedgerKeyContractCode {
+                hash: hash.clone(),
+            })];
+            entries.extend(
+                client
+                    .retry(|| client.get_full_ledger_entries(&code_keys))
+                    .await?
+                    .entries,
+            );
+        }
+
+        let mut snapshot = if self.out.exists() {
+            LedgerSnapshot::read_file(&self.out)
+                .map_err(|e| Error::CannotReadSnapshot(self.out.clone(), e))?
+        } else {
+            LedgerSnapshot::default()
+        };
+        let latest = client.retry(|| client.get_latest_ledger()).await?;
+        snapshot.protocol_version = latest.protocol_version;
+        snapshot.sequence_number = latest.sequence;
+        snapshot.network_id = Sha256::digest(network.network_passphrase.as_bytes()).into();
+        let count = entries.len();
+        for FullLedgerEntry {
+            key,
+            val,
+            last_modified_ledger,
+            live_until_ledger_seq,
+        } in entries
+        {
+            snapshot
+                .ledger_entries
+                .retain(|(existing, _)| **existing != key);
+            let entry = LedgerEntry {
+                last_modified_ledger_seq: last_modified_ledger,
+                data: val,
+                ext: LedgerEntryExt::V0,
+            };
+            snapshot.ledger_entries.push((
+                Box::new(key),
+                (Box::new(entry), Some(live_until_ledger_seq)),
+            ));
+        }
+        snapshot
+            .write_file(&self.out)
+            .map_err(|e| Error::CannotWriteSnapshot(self.out.clone(), e))?;
+        print.checkln(format!(
+            "Saved {count} entries of {contract} to {}",
+            self.out.display()
+        ));
+        Ok(())
+    }
+
+    /// Keys of the data entries given with `--key` or `--key-xdr`.
+    fn data_keys(&self, contract: Contract) -> Result<Vec<LedgerKey>, Error> {
+        if self.key.is_none() && self.key_xdr.is_none() {
+            return Ok(vec![]);
+        }
+        Ok(key::Args {
+            contract_id: None,
+            key: self.key.clone(),
+            key_xdr: self.key_xdr.clone(),
+            wasm: None,
+            wasm_hash: None,
+            durability: self.durability,
+        }
+        .parse_keys(contract)?)
+    }
+}
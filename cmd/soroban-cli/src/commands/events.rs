@@ -1,13 +1,17 @@
 use clap::{arg, command, Parser};
-use std::io;
+use std::{
+    collections::HashMap,
+    io::{self, IsTerminal},
+};
 
 use soroban_env_host::xdr::{self, Limits, ReadXdr};
 
 use super::{
+    address,
     config::{self, locator},
     global, network, NetworkRunnable,
 };
-use crate::rpc;
+use crate::{print::Print, rpc};
 
 #[derive(Parser, Debug, Clone)]
 #[group(skip)]
@@ -137,7 +141,7 @@ pub enum OutputFormat {
 }
 
 impl Cmd {
-    pub async fn run(&mut self) -> Result<(), Error> {
+    pub async fn run(&mut self, global_args: &global::Args) -> Result<(), Error> {
         // Validate that topics are made up of segments.
         for topic in &self.topic_filters {
             for (i, segment) in topic.split(',').enumerate() {
@@ -159,7 +163,13 @@ impl Cmd {
             }
         }
 
-        let response = self.run_against_rpc_server(None, None).await?;
+        let response = self.run_against_rpc_server(Some(global_args), None).await?;
+        // Labels are for people reading the output, scripts get the plain events
+        let labels = if io::stdout().is_terminal() {
+            self.locator.address_labels().unwrap_or_default()
+        } else {
+            HashMap::new()
+        };
 
         for event in &response.events {
             match self.output {
@@ -177,8 +187,15 @@ impl Cmd {
                         })?,
                     );
                 }
-                OutputFormat::Plain => println!("{event}"),
-                OutputFormat::Pretty => event.pretty_print()?,
+                OutputFormat::Plain => {
+                    println!("{}", address::with_labels(&event.to_string(), &labels));
+                }
+                OutputFormat::Pretty => {
+                    event.pretty_print()?;
+                    if let Some(label) = labels.get(&event.contract_id) {
+                        println!("  Contract label: {label}");
+                    }
+                }
             }
         }
         println!("Latest Ledger: {}", response.latest_ledger);
@@ -204,7 +221,7 @@ impl NetworkRunnable for Cmd {
 
     async fn run_against_rpc_server(
         &self,
-        _args: Option<&global::Args>,
+        args: Option<&global::Args>,
         config: Option<&config::Args>,
     ) -> Result<rpc::GetEventsResponse, Error> {
         let start = self.start()?;
@@ -214,9 +231,10 @@ impl NetworkRunnable for Cmd {
             self.network.get(&self.locator)
         }?;
 
-        let client = rpc::Client::new(&network.rpc_url)?;
+        let print = args.map(Print::new).unwrap_or_default();
+        let client = network.rpc_client(&print).await?;
         client
-            .verify_network_passphrase(Some(&network.network_passphrase))
+            .retry(|| client.verify_network_passphrase(Some(&network.network_passphrase)))
             .await?;
 
         let contract_ids: Vec<String> = self
@@ -231,14 +249,15 @@ impl NetworkRunnable for Cmd {
             .collect::<Result<Vec<_>, Error>>()?;
 
         Ok(client
-            .get_events(
-                start,
-                Some(self.event_type),
-                &contract_ids,
-                &self.topic_filters,
-                Some(self.count),
-            )
-            .await
-            .map_err(Error::Rpc)?)
+            .retry(|| {
+                client.get_events(
+                    start.clone(),
+                    Some(self.event_type),
+                    &contract_ids,
+                    &self.topic_filters,
+                    Some(self.count),
+                )
+            })
+            .await?)
     }
 }
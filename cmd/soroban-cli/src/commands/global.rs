@@ -18,7 +18,7 @@ pub struct Args {
     #[arg(long, short = 'q')]
     pub quiet: bool,
 
-    /// Log DEBUG events
+    /// Log DEBUG events, including the RPC requests made and the XDR sent
     #[arg(long, short = 'v')]
     pub verbose: bool,
 
@@ -26,6 +26,10 @@ pub struct Args {
     #[arg(long, visible_alias = "vv")]
     pub very_verbose: bool,
 
+    /// Do not use colors or emoji in output. Also respected via the `NO_COLOR` env var
+    #[arg(long)]
+    pub no_color: bool,
+
     /// List installed plugins. E.g. `stellar-hello`
     #[arg(long)]
     pub list: bool,
@@ -33,6 +37,19 @@ pub struct Args {
     /// Do not cache your simulations and transactions
     #[arg(long, env = "STELLAR_NO_CACHE")]
     pub no_cache: bool,
+
+    /// Format of the status and progress messages written to stderr
+    #[arg(long, value_enum, default_value_t)]
+    pub progress: Progress,
+}
+
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq, clap::ValueEnum)]
+pub enum Progress {
+    /// Messages for people reading the terminal
+    #[default]
+    Human,
+    /// A JSON object per line, including progress events of long operations like build, deploy and network start
+    Json,
 }
 
 #[derive(thiserror::Error, Debug)]
@@ -1,6 +1,9 @@
 use clap::command;
 
-use crate::commands::network;
+use crate::{
+    commands::{global, network},
+    print::Print,
+};
 
 use super::address;
 
@@ -23,11 +26,11 @@ pub struct Cmd {
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
         let addr = self.address.public_key()?;
         self.network
             .get(&self.address.locator)?
-            .fund_address(&addr)
+            .fund_address(&addr, &Print::new(global_args))
             .await?;
         Ok(())
     }
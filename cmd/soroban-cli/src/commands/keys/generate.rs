@@ -1,6 +1,9 @@
 use clap::{arg, command};
 
-use crate::commands::network;
+use crate::{
+    commands::{global, network},
+    print::Print,
+};
 
 use super::super::config::{
     locator,
@@ -51,7 +54,7 @@ pub struct Cmd {
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
         let seed_phrase = if self.default_seed {
             Secret::test_seed_phrase()
         } else {
@@ -67,7 +70,7 @@ impl Cmd {
             let addr = secret.public_key(self.hd_path)?;
             let network = self.network.get(&self.config_locator)?;
             network
-                .fund_address(&addr)
+                .fund_address(&addr, &Print::new(global_args))
                 .await
                 .map_err(|e| {
                     tracing::warn!("fund_address failed: {e}");
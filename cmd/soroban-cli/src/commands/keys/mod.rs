@@ -1,5 +1,7 @@
 use clap::Parser;
 
+use super::global;
+
 pub mod add;
 pub mod address;
 pub mod fund;
@@ -22,7 +24,7 @@ pub enum Cmd {
     Ls(ls::Cmd),
     /// Remove an identity
     Rm(rm::Cmd),
-    /// Given an identity return its private key
+    /// Given an identity return its private key, or its public key with `--public`
     Show(show::Cmd),
 }
 
@@ -48,12 +50,12 @@ pub enum Error {
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
         match self {
             Cmd::Add(cmd) => cmd.run()?,
             Cmd::Address(cmd) => cmd.run()?,
-            Cmd::Fund(cmd) => cmd.run().await?,
-            Cmd::Generate(cmd) => cmd.run().await?,
+            Cmd::Fund(cmd) => cmd.run(global_args).await?,
+            Cmd::Generate(cmd) => cmd.run(global_args).await?,
             Cmd::Ls(cmd) => cmd.run()?,
             Cmd::Rm(cmd) => cmd.run()?,
             Cmd::Show(cmd) => cmd.run()?,
@@ -1,4 +1,4 @@
-use clap::arg;
+use clap::{arg, ValueEnum};
 
 use super::super::config::{locator, secret};
 
@@ -12,6 +12,18 @@ pub enum Error {
 
     #[error(transparent)]
     StrKey(#[from] stellar_strkey::DecodeError),
+
+    #[error("cannot render QR code: {0}")]
+    Qr(#[from] qrcode::types::QrError),
+}
+
+#[derive(ValueEnum, Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub enum Format {
+    /// Stellar strkey, e.g. G... or S...
+    #[default]
+    Strkey,
+    /// Raw 32 bytes as hex
+    Hex,
 }
 
 #[derive(Debug, clap::Parser, Clone)]
@@ -24,16 +36,57 @@ pub struct Cmd {
     #[arg(long)]
     pub hd_path: Option<usize>,
 
+    /// Show the public key instead of the secret key
+    #[arg(long, conflicts_with = "secret")]
+    pub public: bool,
+
+    /// Show the secret key, the default
+    #[arg(long)]
+    pub secret: bool,
+
+    /// Encoding to show the key in
+    #[arg(long, value_enum, default_value_t)]
+    pub format: Format,
+
+    /// Also print the key as a QR code, e.g. for scanning with a mobile wallet
+    #[arg(long)]
+    pub qr: bool,
+
     #[command(flatten)]
     pub locator: locator::Args,
 }
 
 impl Cmd {
     pub fn run(&self) -> Result<(), Error> {
-        println!("{}", self.private_key()?.to_string());
+        let key = self.key()?;
+        println!("{key}");
+        if self.qr {
+            println!("{}", crate::utils::qr_code(&key)?);
+        }
         Ok(())
     }
 
+    /// The requested key in the requested format.
+    pub fn key(&self) -> Result<String, Error> {
+        let private_key = self.private_key()?;
+        Ok(if self.public {
+            let public_key = stellar_strkey::ed25519::PublicKey::from_payload(
+                crate::utils::into_signing_key(&private_key)
+                    .verifying_key()
+                    .as_bytes(),
+            )?;
+            match self.format {
+                Format::Strkey => public_key.to_string(),
+                Format::Hex => hex::encode(public_key.0),
+            }
+        } else {
+            match self.format {
+                Format::Strkey => private_key.to_string(),
+                Format::Hex => hex::encode(private_key.0),
+            }
+        })
+    }
+
     pub fn private_key(&self) -> Result<stellar_strkey::ed25519::PrivateKey, Error> {
         Ok(self
             .locator
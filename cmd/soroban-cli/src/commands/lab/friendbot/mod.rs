@@ -0,0 +1,26 @@
+use clap::Parser;
+
+use crate::commands::global;
+
+pub mod serve;
+
+#[derive(Debug, Parser)]
+pub enum Cmd {
+    /// Run a friendbot HTTP service that funds accounts from a configured identity
+    Serve(serve::Cmd),
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Serve(#[from] serve::Error),
+}
+
+impl Cmd {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        match self {
+            Cmd::Serve(cmd) => cmd.run(global_args).await?,
+        };
+        Ok(())
+    }
+}
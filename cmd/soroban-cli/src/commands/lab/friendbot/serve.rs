@@ -0,0 +1,284 @@
+use std::{
+    collections::HashMap,
+    convert::Infallible,
+    net::{IpAddr, SocketAddr},
+    sync::{Arc, Mutex},
+    time::{Duration, Instant},
+};
+
+use clap::{arg, command, Parser};
+use ed25519_dalek::SigningKey;
+use hyper::{
+    server::conn::AddrStream,
+    service::{make_service_fn, service_fn},
+    Body, Method, Request, Response, Server, StatusCode,
+};
+use serde_json::{json, Value};
+use stellar_strkey::ed25519;
+
+use crate::{
+    commands::{
+        config, global,
+        network::{self, Network, RpcClient},
+    },
+    print::Print,
+    rpc, signer, utils,
+    xdr::{
+        self, AccountId, CreateAccountOp, Memo, MuxedAccount, Operation, OperationBody,
+        Preconditions, PublicKey, SequenceNumber, Transaction, TransactionExt, Uint256,
+    },
+};
+
+/// Fee of the funding transactions, in stroops
+const BASE_FEE: u32 = 100;
+
+#[derive(Parser, Debug, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// Address to listen on
+    #[arg(long, default_value = "127.0.0.1:8000")]
+    pub listen: SocketAddr,
+    /// Starting balance of each funded account, in stroops
+    #[arg(long, default_value = "100000000000")]
+    pub starting_balance: i64,
+    /// Number of accounts a single IP address may fund per quota window, 0 for no limit
+    #[arg(long, default_value = "5")]
+    pub quota: usize,
+    /// Length of the quota window, in seconds
+    #[arg(long, default_value = "3600")]
+    pub quota_window: u64,
+    #[command(flatten)]
+    pub config: config::Args,
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Config(#[from] config::Error),
+    #[error(transparent)]
+    Rpc(#[from] rpc::Error),
+    #[error(transparent)]
+    Network(#[from] network::Error),
+    #[error(transparent)]
+    Signer(#[from] signer::Error),
+    #[error(transparent)]
+    Xdr(#[from] xdr::Error),
+    #[error("serving friendbot on {0}: {1}")]
+    Server(SocketAddr, hyper::Error),
+}
+
+impl Cmd {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
+        let network = self.config.get_network()?;
+        let key = self.config.key_pair()?;
+        let client = network.rpc_client(&print).await?;
+        client
+            .retry(|| client.verify_network_passphrase(Some(&network.network_passphrase)))
+            .await?;
+        let funder = ed25519::PublicKey(key.verifying_key().to_bytes()).to_string();
+        client.retry(|| client.get_account(&funder)).await?;
+
+        let faucet = Arc::new(Faucet {
+            client,
+            network,
+            key,
+            starting_balance: self.starting_balance,
+            quota: Quota::new(self.quota, Duration::from_secs(self.quota_window)),
+            submit: tokio::sync::Mutex::new(()),
+            print,
+        });
+        let make_service = make_service_fn(move |conn: &AddrStream| {
+            let faucet = faucet.clone();
+            let ip = conn.remote_addr().ip();
+            async move {
+                Ok::<_, Infallible>(service_fn(move |req| {
+                    let faucet = faucet.clone();
+                    async move { Ok::<_, Infallible>(faucet.handle(ip, &req).await) }
+                }))
+            }
+        });
+        let server = Server::try_bind(&self.listen)
+            .map_err(|e| Error::Server(self.listen, e))?
+            .serve(make_service);
+        print.globeln(format!(
+            "Friendbot funding accounts from {funder} on http://{}",
+            self.listen
+        ));
+        server
+            .with_graceful_shutdown(async {
+                let _ = tokio::signal::ctrl_c().await;
+            })
+            .await
+            .map_err(|e| Error::Server(self.listen, e))
+    }
+}
+
+struct Faucet {
+    client: RpcClient,
+    network: Network,
+    key: SigningKey,
+    starting_balance: i64,
+    quota: Quota,
+    // Funding transactions share the funder's sequence number, so they are submitted one at a time
+    submit: tokio::sync::Mutex<()>,
+    print: Print,
+}
+
+impl Faucet {
+    /// Answer a request the way the public friendbot does, `GET /?addr=<account>`.
+    async fn handle(&self, ip: IpAddr, req: &Request<Body>) -> Response<Body> {
+        if req.method() != Method::GET || !matches!(req.uri().path(), "/" | "/friendbot") {
+            return respond(StatusCode::NOT_FOUND, &json!({ "detail": "not found" }));
+        }
+        let Some(addr) = req.uri().query().and_then(query_addr) else {
+            return respond(
+                StatusCode::BAD_REQUEST,
+                &json!({ "detail": "missing addr query parameter" }),
+            );
+        };
+        let Ok(addr) = ed25519::PublicKey::from_string(addr) else {
+            return respond(
+                StatusCode::BAD_REQUEST,
+                &json!({ "detail": format!("invalid account address {addr}") }),
+            );
+        };
+        if !self.quota.take(ip, Instant::now()) {
+            return respond(
+                StatusCode::TOO_MANY_REQUESTS,
+                &json!({ "detail": format!("funding quota exceeded for {ip}") }),
+            );
+        }
+        match self.fund(&addr).await {
+            Ok(Some(hash)) => {
+                self.print.checkln(format!("Funded {addr} for {ip}"));
+                respond(StatusCode::OK, &json!({ "successful": true, "hash": hash }))
+            }
+            Ok(None) => respond(
+                StatusCode::BAD_REQUEST,
+                &json!({ "detail": "createAccountAlreadyExist" }),
+            ),
+            Err(e) => {
+                self.print.errorln(format!("Funding {addr} failed: {e}"));
+                respond(
+                    StatusCode::INTERNAL_SERVER_ERROR,
+                    &json!({ "detail": e.to_string() }),
+                )
+            }
+        }
+    }
+
+    /// Create the account, returning the transaction hash, or `None` if it already exists.
+    async fn fund(&self, addr: &ed25519::PublicKey) -> Result<Option<String>, Error> {
+        if self.account_exists(addr).await {
+            return Ok(None);
+        }
+        let _submit = self.submit.lock().await;
+        // A request for the same account may have funded it while this one waited for the lock
+        if self.account_exists(addr).await {
+            return Ok(None);
+        }
+        let funder = self.key.verifying_key().to_bytes();
+        let funder_strkey = ed25519::PublicKey(funder).to_string();
+        let sequence: i64 = self
+            .client
+            .retry(|| self.client.get_account(&funder_strkey))
+            .await?
+            .seq_num
+            .into();
+        let tx = Transaction {
+            source_account: MuxedAccount::Ed25519(Uint256(funder)),
+            fee: BASE_FEE,
+            seq_num: SequenceNumber(sequence + 1),
+            cond: Preconditions::None,
+            memo: Memo::None,
+            operations: vec![Operation {
+                source_account: None,
+                body: OperationBody::CreateAccount(CreateAccountOp {
+                    destination: AccountId(PublicKey::PublicKeyTypeEd25519(Uint256(addr.0))),
+                    starting_balance: self.starting_balance,
+                }),
+            }]
+            .try_into()?,
+            ext: TransactionExt::V0,
+        };
+        let passphrase = &self.network.network_passphrase;
+        let hash = utils::transaction_hash(&tx, passphrase)?;
+        let envelope = signer::sign_tx(&self.key, &tx, passphrase)?;
+        self.client.send_transaction_polling(&envelope).await?;
+        Ok(Some(hex::encode(hash)))
+    }
+
+    async fn account_exists(&self, addr: &ed25519::PublicKey) -> bool {
+        self.client.get_account(&addr.to_string()).await.is_ok()
+    }
+}
+
+/// Number of accounts each IP address has funded in a sliding window.
+struct Quota {
+    limit: usize,
+    window: Duration,
+    funded: Mutex<HashMap<IpAddr, Vec<Instant>>>,
+}
+
+impl Quota {
+    fn new(limit: usize, window: Duration) -> Self {
+        Quota {
+            limit,
+            window,
+            funded: Mutex::default(),
+        }
+    }
+
+    /// Count a funding for `ip`, returning false if it is over its quota.
+    fn take(&self, ip: IpAddr, now: Instant) -> bool {
+        if self.limit == 0 {
+            return true;
+        }
+        let mut funded = self.funded.lock().unwrap();
+        let times = funded.entry(ip).or_default();
+        times.retain(|time| now.duration_since(*time) < self.window);
+        if times.len() >= self.limit {
+            return false;
+        }
+        times.push(now);
+        true
+    }
+}
+
+fn query_addr(query: &str) -> Option<&str> {
+    query.split('&').find_map(|pair| pair.strip_prefix("addr="))
+}
+
+fn respond(status: StatusCode, body: &Value) -> Response<Body> {
+    Response::builder()
+        .status(status)
+        .header("content-type", "application/json")
+        .body(Body::from(body.to_string()))
+        .unwrap()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn quota_per_ip() {
+        let quota = Quota::new(2, Duration::from_secs(60));
+        let a = IpAddr::from([127, 0, 0, 1]);
+        let b = IpAddr::from([127, 0, 0, 2]);
+        let now = Instant::now();
+        assert!(quota.take(a, now));
+        assert!(quota.take(a, now));
+        assert!(!quota.take(a, now));
+        assert!(quota.take(b, now));
+        assert!(quota.take(a, now + Duration::from_secs(61)));
+    }
+
+    #[test]
+    fn addr_from_query() {
+        assert_eq!(query_addr("addr=GABC"), Some("GABC"));
+        assert_eq!(query_addr("x=1&addr=GABC"), Some("GABC"));
+        assert_eq!(query_addr("x=1"), None);
+    }
+}
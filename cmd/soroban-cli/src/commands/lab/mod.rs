@@ -0,0 +1,41 @@
+use clap::Parser;
+
+use super::global;
+
+pub mod friendbot;
+pub mod web_auth;
+pub mod xdr;
+
+#[derive(Debug, Parser)]
+pub enum Cmd {
+    /// Run a friendbot funding service for private test networks
+    #[command(subcommand)]
+    Friendbot(friendbot::Cmd),
+    /// Create and sign SEP-10 web authentication challenges for local testing
+    #[command(subcommand)]
+    WebAuth(web_auth::Cmd),
+    /// Inspect and compare XDR values
+    #[command(subcommand)]
+    Xdr(xdr::Cmd),
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Friendbot(#[from] friendbot::Error),
+    #[error(transparent)]
+    WebAuth(#[from] web_auth::Error),
+    #[error(transparent)]
+    Xdr(#[from] xdr::Error),
+}
+
+impl Cmd {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        match self {
+            Cmd::Friendbot(cmd) => cmd.run(global_args).await?,
+            Cmd::WebAuth(cmd) => cmd.run()?,
+            Cmd::Xdr(cmd) => cmd.run(global_args)?,
+        };
+        Ok(())
+    }
+}
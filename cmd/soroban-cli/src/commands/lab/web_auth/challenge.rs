@@ -0,0 +1,105 @@
+use base64::{engine::general_purpose::STANDARD, Engine as _};
+use clap::{arg, command};
+use rand::Rng;
+
+use crate::{
+    commands::config,
+    signer,
+    xdr::{
+        self, DataValue, Limits, ManageDataOp, Memo, MuxedAccount, Operation, OperationBody,
+        Preconditions, SequenceNumber, TimeBounds, TimePoint, Transaction, TransactionExt, Uint256,
+        WriteXdr,
+    },
+};
+
+use super::{now, WEB_AUTH_DOMAIN_KEY};
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Config(#[from] config::Error),
+    #[error(transparent)]
+    Signer(#[from] signer::Error),
+    #[error(transparent)]
+    Xdr(#[from] xdr::Error),
+    #[error(transparent)]
+    StrKey(#[from] stellar_strkey::DecodeError),
+}
+
+/// Build a SEP-10 challenge, as an anchor's web auth endpoint would, so that
+/// client signing can be tested without running an anchor.
+#[derive(Debug, clap::Parser, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// Account (G...) that is asked to prove it holds its signing key
+    #[arg(long)]
+    pub client_account: String,
+    /// Home domain of the anchor issuing the challenge
+    #[arg(long)]
+    pub home_domain: String,
+    /// Domain of the web auth endpoint, defaults to the home domain
+    #[arg(long)]
+    pub web_auth_domain: Option<String>,
+    /// Seconds the challenge is valid for
+    #[arg(long, default_value = "900")]
+    pub timeout: u64,
+    /// The `--source` account is the server account that signs the challenge
+    #[command(flatten)]
+    pub config: config::Args,
+}
+
+impl Cmd {
+    pub fn run(&self) -> Result<(), Error> {
+        println!("{}", self.challenge()?.to_xdr_base64(Limits::none())?);
+        Ok(())
+    }
+
+    pub fn challenge(&self) -> Result<xdr::TransactionEnvelope, Error> {
+        let server_key = self.config.key_pair()?;
+        let server = MuxedAccount::Ed25519(Uint256(server_key.verifying_key().to_bytes()));
+        let client = stellar_strkey::ed25519::PublicKey::from_string(&self.client_account)?;
+        let web_auth_domain = self.web_auth_domain.as_ref().unwrap_or(&self.home_domain);
+
+        // 48 random bytes encode to the 64 byte base64 nonce SEP-10 expects
+        let mut nonce = [0u8; 48];
+        rand::thread_rng().fill(&mut nonce[..]);
+        let nonce = STANDARD.encode(nonce);
+        let now = now();
+        let tx = Transaction {
+            source_account: server.clone(),
+            fee: 100,
+            seq_num: SequenceNumber(0),
+            cond: Preconditions::Time(TimeBounds {
+                min_time: TimePoint(now),
+                max_time: TimePoint(now + self.timeout),
+            }),
+            memo: Memo::None,
+            operations: vec![
+                manage_data(
+                    MuxedAccount::Ed25519(Uint256(client.0)),
+                    &format!("{} auth", self.home_domain),
+                    nonce.as_bytes(),
+                )?,
+                manage_data(server, WEB_AUTH_DOMAIN_KEY, web_auth_domain.as_bytes())?,
+            ]
+            .try_into()?,
+            ext: TransactionExt::V0,
+        };
+        let network = self.config.get_network()?;
+        Ok(signer::sign_tx(
+            &server_key,
+            &tx,
+            &network.network_passphrase,
+        )?)
+    }
+}
+
+fn manage_data(source: MuxedAccount, name: &str, value: &[u8]) -> Result<Operation, Error> {
+    Ok(Operation {
+        source_account: Some(source),
+        body: OperationBody::ManageData(ManageDataOp {
+            data_name: name.try_into()?,
+            data_value: Some(DataValue(value.to_vec().try_into()?)),
+        }),
+    })
+}
@@ -0,0 +1,190 @@
+use std::time::{SystemTime, UNIX_EPOCH};
+
+use clap::Parser;
+
+use crate::{
+    signer,
+    xdr::{
+        ManageDataOp, MuxedAccount, Operation, OperationBody, Preconditions, TimeBounds,
+        Transaction, TransactionEnvelope, TransactionV1Envelope, Uint256,
+    },
+};
+
+pub mod challenge;
+pub mod sign;
+pub mod token;
+
+/// Name of the manage data operation that carries the web auth domain.
+pub const WEB_AUTH_DOMAIN_KEY: &str = "web_auth_domain";
+
+#[derive(Debug, Parser)]
+pub enum Cmd {
+    /// Build a SEP-10 challenge transaction for a client account, signed by the server account
+    Challenge(challenge::Cmd),
+    /// Sign a SEP-10 challenge transaction read from stdin with the client account
+    Sign(sign::Cmd),
+    /// Fetch a challenge from a SEP-10 endpoint, sign it, and print the returned auth token
+    Token(token::Cmd),
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Challenge(#[from] challenge::Error),
+    #[error(transparent)]
+    Sign(#[from] sign::Error),
+    #[error(transparent)]
+    Token(#[from] token::Error),
+}
+
+impl Cmd {
+    pub fn run(&self) -> Result<(), Error> {
+        match self {
+            Cmd::Challenge(cmd) => cmd.run()?,
+            Cmd::Sign(cmd) => cmd.run()?,
+            Cmd::Token(cmd) => cmd.run()?,
+        };
+        Ok(())
+    }
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum InvalidChallenge {
+    #[error("only transaction v1 envelopes are supported")]
+    EnvelopeType,
+    #[error("challenge sequence number must be 0, got {0}")]
+    SequenceNumber(i64),
+    #[error("challenge source account is not the server account")]
+    ServerAccount,
+    #[error("challenge must have time bounds")]
+    MissingTimeBounds,
+    #[error("challenge expired at {0}")]
+    Expired(u64),
+    #[error("challenge first operation must be a manage data operation named \"<home domain> auth\" with the client account as source")]
+    FirstOperation,
+    #[error("challenge operations must all be manage data operations")]
+    NotManageData,
+}
+
+/// Check that `envelope` is a SEP-10 challenge for the `client` account, and, when given, that it
+/// was issued by the `server` account.
+pub fn validate_challenge(
+    envelope: &TransactionEnvelope,
+    client: &[u8; 32],
+    server: Option<&[u8; 32]>,
+) -> Result<(), InvalidChallenge> {
+    let TransactionEnvelope::Tx(TransactionV1Envelope { tx, .. }) = envelope else {
+        return Err(InvalidChallenge::EnvelopeType);
+    };
+    if tx.seq_num.0 != 0 {
+        return Err(InvalidChallenge::SequenceNumber(tx.seq_num.0));
+    }
+    if let Some(server) = server {
+        if tx.source_account != MuxedAccount::Ed25519(Uint256(*server)) {
+            return Err(InvalidChallenge::ServerAccount);
+        }
+    }
+    let Preconditions::Time(TimeBounds { max_time, .. }) = &tx.cond else {
+        return Err(InvalidChallenge::MissingTimeBounds);
+    };
+    if max_time.0 != 0 && max_time.0 < now() {
+        return Err(InvalidChallenge::Expired(max_time.0));
+    }
+    match tx.operations.first() {
+        Some(Operation {
+            source_account: Some(MuxedAccount::Ed25519(Uint256(source))),
+            body: OperationBody::ManageData(ManageDataOp { data_name, .. }),
+        }) if source == client && data_name.to_utf8_string_lossy().ends_with(" auth") => {}
+        _ => return Err(InvalidChallenge::FirstOperation),
+    }
+    if !tx
+        .operations
+        .iter()
+        .all(|op| matches!(op.body, OperationBody::ManageData(_)))
+    {
+        return Err(InvalidChallenge::NotManageData);
+    }
+    Ok(())
+}
+
+/// Add a signature from `key` to the signatures already on the envelope.
+pub fn add_signature(
+    tx: &Transaction,
+    envelope: TransactionEnvelope,
+    key: &ed25519_dalek::SigningKey,
+    network_passphrase: &str,
+) -> Result<TransactionEnvelope, signer::Error> {
+    let TransactionEnvelope::Tx(TransactionV1Envelope {
+        signatures: new_signatures,
+        ..
+    }) = signer::sign_tx(key, tx, network_passphrase)?
+    else {
+        unreachable!()
+    };
+    let mut signatures: Vec<_> = match envelope {
+        TransactionEnvelope::Tx(TransactionV1Envelope { signatures, .. }) => signatures.into(),
+        _ => vec![],
+    };
+    signatures.extend(new_signatures.iter().cloned());
+    Ok(TransactionEnvelope::Tx(TransactionV1Envelope {
+        tx: tx.clone(),
+        signatures: signatures.try_into()?,
+    }))
+}
+
+fn now() -> u64 {
+    SystemTime::now()
+        .duration_since(UNIX_EPOCH)
+        .unwrap_or_default()
+        .as_secs()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::xdr::{DataValue, Memo, SequenceNumber, TimePoint, TransactionExt};
+
+    fn challenge(client: [u8; 32], seq_num: i64) -> TransactionEnvelope {
+        TransactionEnvelope::Tx(TransactionV1Envelope {
+            tx: Transaction {
+                source_account: MuxedAccount::Ed25519(Uint256([1; 32])),
+                fee: 100,
+                seq_num: SequenceNumber(seq_num),
+                cond: Preconditions::Time(TimeBounds {
+                    min_time: TimePoint(0),
+                    max_time: TimePoint(now() + 900),
+                }),
+                memo: Memo::None,
+                operations: vec![Operation {
+                    source_account: Some(MuxedAccount::Ed25519(Uint256(client))),
+                    body: OperationBody::ManageData(ManageDataOp {
+                        data_name: "example.com auth".try_into().unwrap(),
+                        data_value: Some(DataValue(vec![0; 64].try_into().unwrap())),
+                    }),
+                }]
+                .try_into()
+                .unwrap(),
+                ext: TransactionExt::V0,
+            },
+            signatures: vec![].try_into().unwrap(),
+        })
+    }
+
+    #[test]
+    fn validates_challenge() {
+        let client = [2; 32];
+        assert!(validate_challenge(&challenge(client, 0), &client, Some(&[1; 32])).is_ok());
+        assert!(matches!(
+            validate_challenge(&challenge(client, 1), &client, None),
+            Err(InvalidChallenge::SequenceNumber(1))
+        ));
+        assert!(matches!(
+            validate_challenge(&challenge(client, 0), &[3; 32], None),
+            Err(InvalidChallenge::FirstOperation)
+        ));
+        assert!(matches!(
+            validate_challenge(&challenge(client, 0), &client, Some(&[3; 32])),
+            Err(InvalidChallenge::ServerAccount)
+        ));
+    }
+}
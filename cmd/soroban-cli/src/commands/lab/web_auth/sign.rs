@@ -0,0 +1,81 @@
+use clap::{arg, command};
+
+use crate::{
+    commands::{config, tx},
+    signer,
+    xdr::{self, Limits, WriteXdr},
+};
+
+use super::{add_signature, validate_challenge, InvalidChallenge};
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Config(#[from] config::Error),
+    #[error(transparent)]
+    XdrArgs(#[from] tx::xdr::Error),
+    #[error(transparent)]
+    InvalidChallenge(#[from] InvalidChallenge),
+    #[error(transparent)]
+    Signer(#[from] signer::Error),
+    #[error(transparent)]
+    Xdr(#[from] xdr::Error),
+    #[error(transparent)]
+    StrKey(#[from] stellar_strkey::DecodeError),
+    #[error("cannot render QR code: {0}")]
+    Qr(#[from] qrcode::types::QrError),
+}
+
+/// Sign a SEP-10 challenge transaction envelope from stdin
+/// e.g. `stellar lab web-auth challenge ... | stellar lab web-auth sign --source alice`
+#[derive(Debug, clap::Parser, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// Server account (G...) the challenge must have been issued by
+    #[arg(long)]
+    pub server_account: Option<String>,
+    /// Also print the signed envelope as a QR code, e.g. for scanning with a mobile wallet
+    #[arg(long)]
+    pub qr: bool,
+    /// The `--source` account is the client account the challenge was issued for
+    #[command(flatten)]
+    pub config: config::Args,
+}
+
+impl Cmd {
+    pub fn run(&self) -> Result<(), Error> {
+        let envelope = tx::xdr::tx_envelope_from_stdin()?;
+        let signed = self.sign(envelope)?;
+        let signed = signed.to_xdr_base64(Limits::none())?;
+        println!("{signed}");
+        if self.qr {
+            println!("{}", crate::utils::qr_code(&signed)?);
+        }
+        Ok(())
+    }
+
+    pub fn sign(
+        &self,
+        envelope: xdr::TransactionEnvelope,
+    ) -> Result<xdr::TransactionEnvelope, Error> {
+        let key = self.config.key_pair()?;
+        let server = self
+            .server_account
+            .as_deref()
+            .map(stellar_strkey::ed25519::PublicKey::from_string)
+            .transpose()?;
+        validate_challenge(
+            &envelope,
+            &key.verifying_key().to_bytes(),
+            server.as_ref().map(|s| &s.0),
+        )?;
+        let tx = tx::xdr::unwrap_envelope_v1(envelope.clone())?;
+        let network = self.config.get_network()?;
+        Ok(add_signature(
+            &tx,
+            envelope,
+            &key,
+            &network.network_passphrase,
+        )?)
+    }
+}
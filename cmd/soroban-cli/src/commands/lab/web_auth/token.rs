@@ -0,0 +1,100 @@
+use clap::{arg, command};
+use serde::Deserialize;
+use serde_json::json;
+
+use crate::{
+    commands::config,
+    xdr::{self, Limits, ReadXdr, TransactionEnvelope, WriteXdr},
+};
+
+use super::sign;
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Config(#[from] config::Error),
+    #[error(transparent)]
+    Sign(#[from] sign::Error),
+    #[error(transparent)]
+    Xdr(#[from] xdr::Error),
+    #[error("request to {0} failed: {1}")]
+    Request(String, Box<ureq::Error>),
+    #[error("parsing response from {0}: {1}")]
+    Response(String, std::io::Error),
+    #[error(
+        "challenge is for network {challenge:?}, but the configured network is {configured:?}"
+    )]
+    NetworkMismatch {
+        challenge: String,
+        configured: String,
+    },
+}
+
+/// Run the client side of SEP-10 against a web auth endpoint and print the
+/// returned auth token (a JWT) to stdout
+#[derive(Debug, clap::Parser, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// Web auth endpoint, e.g. https://testanchor.stellar.org/auth
+    #[arg(long)]
+    pub url: String,
+    /// Home domain to request the challenge for, when the server serves several
+    #[arg(long)]
+    pub home_domain: Option<String>,
+    #[command(flatten)]
+    pub sign: sign::Cmd,
+}
+
+#[derive(Deserialize)]
+struct ChallengeResponse {
+    transaction: String,
+    network_passphrase: Option<String>,
+}
+
+#[derive(Deserialize)]
+struct TokenResponse {
+    token: String,
+}
+
+impl Cmd {
+    pub fn run(&self) -> Result<(), Error> {
+        println!("{}", self.token()?);
+        Ok(())
+    }
+
+    pub fn token(&self) -> Result<String, Error> {
+        let config = &self.sign.config;
+        let account =
+            stellar_strkey::ed25519::PublicKey(config.key_pair()?.verifying_key().to_bytes())
+                .to_string();
+        let mut request = ureq::get(&self.url).query("account", &account);
+        if let Some(home_domain) = &self.home_domain {
+            request = request.query("home_domain", home_domain);
+        }
+        let challenge: ChallengeResponse = request
+            .call()
+            .map_err(|e| Error::Request(self.url.clone(), Box::new(e)))?
+            .into_json()
+            .map_err(|e| Error::Response(self.url.clone(), e))?;
+
+        let configured = config.get_network()?.network_passphrase;
+        if let Some(passphrase) = challenge.network_passphrase {
+            if passphrase != configured {
+                return Err(Error::NetworkMismatch {
+                    challenge: passphrase,
+                    configured,
+                });
+            }
+        }
+
+        let envelope =
+            TransactionEnvelope::from_xdr_base64(&challenge.transaction, Limits::none())?;
+        let signed = self.sign.sign(envelope)?;
+        let token: TokenResponse = ureq::post(&self.url)
+            .send_json(json!({ "transaction": signed.to_xdr_base64(Limits::none())? }))
+            .map_err(|e| Error::Request(self.url.clone(), Box::new(e)))?
+            .into_json()
+            .map_err(|e| Error::Response(self.url.clone(), e))?;
+        Ok(token.token)
+    }
+}
@@ -0,0 +1,228 @@
+use std::{
+    io::{self, IsTerminal, Write},
+    str::FromStr,
+};
+
+use clap::{arg, command, Parser, ValueEnum};
+use serde_json::{json, Value};
+use termcolor::{Color, ColorChoice, ColorSpec, StandardStream, WriteColor};
+
+use crate::{
+    commands::global,
+    xdr::{self, Limited, Limits, Type, TypeVariant},
+};
+
+#[derive(Parser, Debug, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// XDR type of both values, e.g. TransactionEnvelope or LedgerFootprint
+    #[arg(long = "type")]
+    pub type_: String,
+    /// First value, base64 encoded
+    pub a: String,
+    /// Second value, base64 encoded
+    pub b: String,
+    /// Format of the differences
+    #[arg(long, value_enum, default_value = "text")]
+    pub output: Output,
+}
+
+#[derive(Clone, Copy, Debug, Eq, Hash, PartialEq, ValueEnum)]
+pub enum Output {
+    /// One line per differing field, colored when printed to a terminal
+    Text,
+    /// JSON array of the differing fields
+    Json,
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error("unknown XDR type {0}")]
+    UnknownType(String),
+    #[error("decoding {0} value as {1}: {2}")]
+    Decode(&'static str, String, xdr::Error),
+    #[error(transparent)]
+    Json(#[from] serde_json::Error),
+    #[error(transparent)]
+    Io(#[from] io::Error),
+}
+
+/// A field that differs between the two values, addressed by its path, e.g. `tx.operations[0]`.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum Change {
+    Added {
+        path: String,
+        new: Value,
+    },
+    Removed {
+        path: String,
+        old: Value,
+    },
+    Changed {
+        path: String,
+        old: Value,
+        new: Value,
+    },
+}
+
+impl Cmd {
+    pub fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let variant = TypeVariant::from_str(&self.type_)
+            .map_err(|_| Error::UnknownType(self.type_.clone()))?;
+        let a = serde_json::to_value(self.decode("first", variant, &self.a)?)?;
+        let b = serde_json::to_value(self.decode("second", variant, &self.b)?)?;
+        let mut changes = vec![];
+        diff("", &a, &b, &mut changes);
+        match self.output {
+            Output::Json => {
+                let changes = changes.iter().map(Change::to_json).collect::<Vec<_>>();
+                println!("{}", Value::Array(changes));
+            }
+            Output::Text => {
+                let color = !global_args.no_color
+                    && std::env::var_os("NO_COLOR").is_none()
+                    && io::stdout().is_terminal();
+                print_text(&changes, color)?;
+            }
+        }
+        Ok(())
+    }
+
+    fn decode(
+        &self,
+        which: &'static str,
+        variant: TypeVariant,
+        value: &str,
+    ) -> Result<Type, Error> {
+        Type::read_xdr_base64_to_end(
+            variant,
+            &mut Limited::new(value.trim().as_bytes(), Limits::none()),
+        )
+        .map_err(|e| Error::Decode(which, self.type_.clone(), e))
+    }
+}
+
+impl Change {
+    fn to_json(&self) -> Value {
+        match self {
+            Change::Added { path, new } => json!({ "path": path, "change": "added", "new": new }),
+            Change::Removed { path, old } => {
+                json!({ "path": path, "change": "removed", "old": old })
+            }
+            Change::Changed { path, old, new } => {
+                json!({ "path": path, "change": "changed", "old": old, "new": new })
+            }
+        }
+    }
+}
+
+/// Collect the fields of `b` that differ from `a`, descending into objects and arrays so that
+/// only the innermost differing fields are reported.
+pub fn diff(path: &str, a: &Value, b: &Value, changes: &mut Vec<Change>) {
+    match (a, b) {
+        (Value::Object(a), Value::Object(b)) => {
+            for (key, old) in a {
+                let path = field(path, key);
+                match b.get(key) {
+                    Some(new) => diff(&path, old, new, changes),
+                    None => changes.push(Change::Removed {
+                        path,
+                        old: old.clone(),
+                    }),
+                }
+            }
+            for (key, new) in b {
+                if !a.contains_key(key) {
+                    changes.push(Change::Added {
+                        path: field(path, key),
+                        new: new.clone(),
+                    });
+                }
+            }
+        }
+        (Value::Array(a), Value::Array(b)) => {
+            for i in 0..a.len().max(b.len()) {
+                let path = format!("{path}[{i}]");
+                match (a.get(i), b.get(i)) {
+                    (Some(old), Some(new)) => diff(&path, old, new, changes),
+                    (Some(old), None) => changes.push(Change::Removed {
+                        path,
+                        old: old.clone(),
+                    }),
+                    (None, Some(new)) => changes.push(Change::Added {
+                        path,
+                        new: new.clone(),
+                    }),
+                    (None, None) => {}
+                }
+            }
+        }
+        _ if a != b => changes.push(Change::Changed {
+            path: path.to_string(),
+            old: a.clone(),
+            new: b.clone(),
+        }),
+        _ => {}
+    }
+}
+
+fn field(path: &str, key: &str) -> String {
+    if path.is_empty() {
+        key.to_string()
+    } else {
+        format!("{path}.{key}")
+    }
+}
+
+fn print_text(changes: &[Change], color: bool) -> Result<(), Error> {
+    let mut stdout = StandardStream::stdout(if color {
+        ColorChoice::Always
+    } else {
+        ColorChoice::Never
+    });
+    for change in changes {
+        let (fg, line) = match change {
+            Change::Added { path, new } => (Color::Green, format!("+ {path}: {new}")),
+            Change::Removed { path, old } => (Color::Red, format!("- {path}: {old}")),
+            Change::Changed { path, old, new } => {
+                (Color::Yellow, format!("~ {path}: {old} -> {new}"))
+            }
+        };
+        stdout.set_color(ColorSpec::new().set_fg(Some(fg)))?;
+        write!(stdout, "{line}")?;
+        stdout.reset()?;
+        writeln!(stdout)?;
+    }
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn reports_innermost_differences() {
+        let a = json!({ "fee": 100, "ops": [{ "amount": 1 }], "memo": "none" });
+        let b = json!({ "fee": 200, "ops": [{ "amount": 1 }, { "amount": 2 }], "cond": "none" });
+        let mut changes = vec![];
+        diff("", &a, &b, &mut changes);
+        assert_eq!(changes.len(), 4);
+        assert!(changes.contains(&Change::Changed {
+            path: "fee".to_string(),
+            old: json!(100),
+            new: json!(200)
+        }));
+        assert!(changes.contains(&Change::Removed {
+            path: "memo".to_string(),
+            old: json!("none")
+        }));
+        assert!(changes.contains(&Change::Added {
+            path: "ops[1]".to_string(),
+            new: json!({ "amount": 2 })
+        }));
+        assert!(changes.contains(&Change::Added {
+            path: "cond".to_string(),
+            new: json!("none")
+        }));
+    }
+}
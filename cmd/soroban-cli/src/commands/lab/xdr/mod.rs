@@ -0,0 +1,26 @@
+use clap::Parser;
+
+use crate::commands::global;
+
+pub mod diff;
+
+#[derive(Debug, Parser)]
+pub enum Cmd {
+    /// Decode two XDR values of the same type and print the fields that differ
+    Diff(diff::Cmd),
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Diff(#[from] diff::Error),
+}
+
+impl Cmd {
+    pub fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        match self {
+            Cmd::Diff(cmd) => cmd.run(global_args)?,
+        };
+        Ok(())
+    }
+}
@@ -3,6 +3,7 @@ use std::str::FromStr;
 use async_trait::async_trait;
 use clap::{command, error::ErrorKind, CommandFactory, FromArgMatches, Parser};
 
+pub mod address;
 pub mod cache;
 pub mod completion;
 pub mod config;
@@ -10,8 +11,12 @@ pub mod contract;
 pub mod events;
 pub mod global;
 pub mod keys;
+pub mod lab;
 pub mod network;
 pub mod plugin;
+pub mod policy;
+pub mod project;
+pub mod test;
 pub mod tx;
 pub mod version;
 
@@ -96,13 +101,18 @@ impl Root {
         match &mut self.cmd {
             Cmd::Completion(completion) => completion.run(),
             Cmd::Contract(contract) => contract.run(&self.global_args).await?,
-            Cmd::Events(events) => events.run().await?,
+            Cmd::Events(events) => events.run(&self.global_args).await?,
             Cmd::Xdr(xdr) => xdr.run()?,
-            Cmd::Network(network) => network.run().await?,
+            Cmd::Network(network) => network.run(&self.global_args).await?,
             Cmd::Version(version) => version.run(),
-            Cmd::Keys(id) => id.run().await?,
+            Cmd::Keys(id) => id.run(&self.global_args).await?,
+            Cmd::Address(address) => address.run()?,
             Cmd::Tx(tx) => tx.run(&self.global_args).await?,
             Cmd::Cache(data) => data.run()?,
+            Cmd::Test(test) => test.run(&self.global_args).await?,
+            Cmd::Lab(lab) => lab.run(&self.global_args).await?,
+            Cmd::Policy(policy) => policy.run(&self.global_args)?,
+            Cmd::Project(project) => project.run(&self.global_args).await?,
         };
         Ok(())
     }
@@ -129,6 +139,9 @@ pub enum Cmd {
     /// Create and manage identities including keys and addresses
     #[command(subcommand)]
     Keys(keys::Cmd),
+    /// Label account and contract addresses to show their names in output
+    #[command(subcommand)]
+    Address(address::Cmd),
     /// Decode and encode XDR
     Xdr(stellar_xdr::cli::Root),
     /// Start and configure networks
@@ -142,6 +155,18 @@ pub enum Cmd {
     /// Cache for transactions and contract specs
     #[command(subcommand)]
     Cache(cache::Cmd),
+    /// Run tests against ephemeral networks
+    #[command(subcommand)]
+    Test(test::Cmd),
+    /// Utilities for testing integrations with the wider Stellar ecosystem
+    #[command(subcommand)]
+    Lab(lab::Cmd),
+    /// Check contracts against the project's deployment policy
+    #[command(subcommand)]
+    Policy(policy::Cmd),
+    /// Check the contract project's setup
+    #[command(subcommand)]
+    Project(project::Cmd),
 }
 
 #[derive(thiserror::Error, Debug)]
@@ -154,6 +179,8 @@ pub enum Error {
     #[error(transparent)]
     Keys(#[from] keys::Error),
     #[error(transparent)]
+    Address(#[from] address::Error),
+    #[error(transparent)]
     Xdr(#[from] stellar_xdr::cli::Error),
     #[error(transparent)]
     Clap(#[from] clap::error::Error),
@@ -165,6 +192,14 @@ pub enum Error {
     Tx(#[from] tx::Error),
     #[error(transparent)]
     Cache(#[from] cache::Error),
+    #[error(transparent)]
+    Test(#[from] test::Error),
+    #[error(transparent)]
+    Lab(#[from] lab::Error),
+    #[error(transparent)]
+    Policy(#[from] policy::Error),
+    #[error(transparent)]
+    Project(#[from] project::Error),
 }
 
 #[async_trait]
@@ -1,6 +1,9 @@
+use crate::commands::global;
+
 pub(crate) mod logs;
-mod shared;
+pub(crate) mod shared;
 pub(crate) mod start;
+pub(crate) mod status;
 pub(crate) mod stop;
 
 // TODO: remove once `network start` is removed
@@ -19,8 +22,12 @@ pub enum Cmd {
     /// stellar network start <NETWORK> [OPTIONS]
     ///
     /// By default, when starting a testnet container, without any optional arguments, it will run the equivalent of the following docker command:
-    /// docker run --rm -p 8000:8000 --name stellar stellar/quickstart:testing --testnet --enable-soroban-rpc
+    /// docker run --restart unless-stopped -v stellar-testnet-data:/opt/stellar -p 8000:8000 --name stellar-testnet stellar/quickstart:testing --testnet --enable-soroban-rpc
     Start(start::Cmd),
+    /// Show the state and health of a network container
+    ///
+    /// Containers are restarted by docker if they crash, and report unhealthy when RPC stops answering.
+    Status(status::Cmd),
     /// Stop a network started with `network container start`. For example, if you ran `network container start local`, you can use `network container stop local` to stop it.
     Stop(stop::Cmd),
 }
@@ -33,16 +40,20 @@ pub enum Error {
     #[error(transparent)]
     Start(#[from] start::Error),
 
+    #[error(transparent)]
+    Status(#[from] status::Error),
+
     #[error(transparent)]
     Stop(#[from] stop::Error),
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
         match &self {
-            Cmd::Logs(cmd) => cmd.run().await?,
-            Cmd::Start(cmd) => cmd.run().await?,
-            Cmd::Stop(cmd) => cmd.run().await?,
+            Cmd::Logs(cmd) => cmd.run(global_args).await?,
+            Cmd::Start(cmd) => cmd.run(global_args).await?,
+            Cmd::Status(cmd) => cmd.run(global_args).await?,
+            Cmd::Stop(cmd) => cmd.run(global_args).await?,
         }
         Ok(())
     }
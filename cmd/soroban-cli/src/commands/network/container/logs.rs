@@ -1,7 +1,13 @@
 use futures_util::TryStreamExt;
 
-use crate::commands::network::container::shared::{
-    connect_to_docker, Error as ConnectionError, Network, DOCKER_HOST_HELP,
+use crate::{
+    commands::{
+        global,
+        network::container::shared::{
+            connect_to_docker, Error as ConnectionError, Network, DOCKER_HOST_HELP,
+        },
+    },
+    print::Print,
 };
 
 #[derive(thiserror::Error, Debug)]
@@ -23,9 +29,10 @@ pub struct Cmd {
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
         let container_name = format!("stellar-{}", self.network);
-        let docker = connect_to_docker(&self.docker_host).await?;
+        let docker = connect_to_docker(&self.docker_host, &print).await?;
         let logs_stream = &mut docker.logs(
             &container_name,
             Some(bollard::container::LogsOptions {
@@ -1,18 +1,32 @@
-use std::collections::HashMap;
+use std::{collections::HashMap, path::PathBuf};
 
 use bollard::{
-    container::{Config, CreateContainerOptions, StartContainerOptions},
+    container::{Config, CreateContainerOptions, RemoveContainerOptions, StartContainerOptions},
     image::CreateImageOptions,
-    service::{HostConfig, PortBinding},
+    service::{HealthConfig, HostConfig, PortBinding, RestartPolicy, RestartPolicyNameEnum},
+    volume::{CreateVolumeOptions, RemoveVolumeOptions},
+    Docker,
 };
 use futures_util::TryStreamExt;
 
-use crate::commands::network::container::shared::{
-    connect_to_docker, Error as ConnectionError, Network, DOCKER_HOST_HELP,
+use crate::{
+    commands::{
+        global,
+        network::container::shared::{
+            connect_to_docker, is_not_found, Error as ConnectionError, Network, DOCKER_HOST_HELP,
+        },
+    },
+    env_file,
+    print::Print,
 };
 
 const DEFAULT_PORT_MAPPING: &str = "8000:8000";
 const DOCKER_IMAGE: &str = "docker.io/stellar/quickstart";
+// Where quickstart keeps the node's databases and history; mounting a volume here persists the chain
+const DATA_DIR: &str = "/opt/stellar";
+// Quickstart serves RPC on port 8000 inside the container regardless of the host port mapping
+const HEALTH_CHECK: &str = r#"curl -sf -X POST -H 'Content-Type: application/json' -d '{"jsonrpc":"2.0","id":1,"method":"getHealth"}' http://localhost:8000/soroban/rpc"#;
+const SECOND_IN_NANOS: i64 = 1_000_000_000;
 
 #[derive(thiserror::Error, Debug)]
 pub enum Error {
@@ -21,6 +35,12 @@ pub enum Error {
 
     #[error("⛔ ️Failed to create container: {0}")]
     BollardErr(#[from] bollard::errors::Error),
+
+    #[error("⛔ ️Container {0} is already running")]
+    AlreadyRunning(String),
+
+    #[error(transparent)]
+    EnvFile(#[from] env_file::Error),
 }
 
 #[derive(Debug, clap::Parser, Clone)]
@@ -46,19 +66,92 @@ pub struct Cmd {
     /// Optional argument to specify the protocol version for the local network only
     #[arg(short = 'v', long)]
     pub protocol_version: Option<String>,
+
+    /// Wipe the chain data kept from previous runs of this network and start from scratch
+    #[arg(long)]
+    pub reset: bool,
+
+    /// Shell-sourceable env file to set RPC_URL and NETWORK_PASSPHRASE of the network in, e.g. `--export-env .env`
+    #[arg(long, value_name = "FILE")]
+    pub export_env: Option<PathBuf>,
+
+    /// Volume to keep the chain data in instead of the network's own, so that throwaway networks
+    /// like the one of `test e2e` don't share state with the one people work with
+    #[arg(skip)]
+    pub volume: Option<String>,
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
-        println!("ℹ️  Starting {} network", &self.network);
-        run_docker_command(self).await
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
+        print.infoln(format!("Starting {} network", &self.network));
+        run_docker_command(self, &print).await?;
+        if let Some(path) = &self.export_env {
+            env_file::export(
+                path,
+                &[
+                    ("RPC_URL".to_string(), self.rpc_url()),
+                    (
+                        "NETWORK_PASSPHRASE".to_string(),
+                        self.network.passphrase().to_string(),
+                    ),
+                ],
+            )?;
+            print.saveln(format!(
+                "Saved the network's RPC URL and passphrase to {path:?}"
+            ));
+        }
+        Ok(())
+    }
+
+    /// RPC endpoint on the host, through the port mapped to the container's port 8000.
+    fn rpc_url(&self) -> String {
+        let port = self
+            .ports_mapping
+            .iter()
+            .find_map(|mapping| match split_port_mapping(mapping) {
+                (_, host_port, "8000") => Some(host_port),
+                _ => None,
+            })
+            .unwrap_or("8000");
+        format!("http://localhost:{port}/soroban/rpc")
     }
 }
 
-async fn run_docker_command(cmd: &Cmd) -> Result<(), Error> {
-    let docker = connect_to_docker(&cmd.docker_host).await?;
+async fn run_docker_command(cmd: &Cmd, print: &Print) -> Result<(), Error> {
+    let docker = connect_to_docker(&cmd.docker_host, print).await?;
+    let container_name = cmd.network.container_name();
+    remove_stopped_container(&docker, &container_name).await?;
 
-    let image = get_image_name(cmd);
+    let volume_name = cmd
+        .volume
+        .clone()
+        .unwrap_or_else(|| cmd.network.volume_name());
+    if cmd.reset {
+        match docker
+            .remove_volume(&volume_name, None::<RemoveVolumeOptions>)
+            .await
+        {
+            Ok(()) => print.infoln(format!("Removed chain data in volume {volume_name}")),
+            Err(e) if is_not_found(&e) => {}
+            Err(e) => return Err(e.into()),
+        }
+    }
+    print.progressln(
+        "network start",
+        1,
+        3,
+        format!("Creating volume {volume_name}"),
+    );
+    docker
+        .create_volume(CreateVolumeOptions {
+            name: volume_name.clone(),
+            ..Default::default()
+        })
+        .await?;
+
+    let image = get_image_name(cmd, print);
+    print.progressln("network start", 2, 3, format!("Pulling image {image}"));
     docker
         .create_image(
             Some(CreateImageOptions {
@@ -71,6 +164,12 @@ async fn run_docker_command(cmd: &Cmd) -> Result<(), Error> {
         .try_collect::<Vec<_>>()
         .await?;
 
+    print.progressln(
+        "network start",
+        3,
+        3,
+        format!("Starting container {container_name}"),
+    );
     let container_args = get_container_args(cmd);
     let port_mapping = get_port_mapping(cmd);
 
@@ -79,15 +178,27 @@ async fn run_docker_command(cmd: &Cmd) -> Result<(), Error> {
         cmd: Some(container_args),
         attach_stdout: Some(true),
         attach_stderr: Some(true),
+        healthcheck: Some(HealthConfig {
+            test: Some(vec!["CMD-SHELL".to_string(), HEALTH_CHECK.to_string()]),
+            interval: Some(10 * SECOND_IN_NANOS),
+            timeout: Some(5 * SECOND_IN_NANOS),
+            retries: Some(3),
+            start_period: Some(60 * SECOND_IN_NANOS),
+            ..Default::default()
+        }),
         host_config: Some(HostConfig {
-            auto_remove: Some(true),
+            // docker restarts the container if it crashes, until it is stopped with `network container stop`
+            restart_policy: Some(RestartPolicy {
+                name: Some(RestartPolicyNameEnum::UNLESS_STOPPED),
+                maximum_retry_count: None,
+            }),
+            binds: Some(vec![format!("{volume_name}:{DATA_DIR}")]),
             port_bindings: Some(port_mapping),
             ..Default::default()
         }),
         ..Default::default()
     };
 
-    let container_name = format!("stellar-{}", cmd.network);
     let create_container_response = docker
         .create_container(
             Some(CreateContainerOptions {
@@ -104,9 +215,12 @@ async fn run_docker_command(cmd: &Cmd) -> Result<(), Error> {
             None::<StartContainerOptions<String>>,
         )
         .await?;
-    println!("✅ Container started: {container_name}");
+    print.checkln(format!("Container started: {container_name}"));
+    print.infoln(format!(
+        "Chain data is kept in volume {volume_name}, start with --reset to wipe it"
+    ));
     let stop_message = format!(
-        "ℹ️  To stop this container run: stellar network stop {network} {additional_flags}",
+        "To stop this container run: stellar network stop {network} {additional_flags}",
         network = &cmd.network,
         additional_flags = if cmd.docker_host.is_some() {
             format!("--docker-host {}", cmd.docker_host.as_ref().unwrap())
@@ -115,7 +229,24 @@ async fn run_docker_command(cmd: &Cmd) -> Result<(), Error> {
         }
     );
 
-    println!("{stop_message}");
+    print.infoln(stop_message);
+    Ok(())
+}
+
+// Containers are no longer removed when they exit, so one left behind by a crash or a stop from
+// outside the CLI has to be removed before a new one can take its name.
+async fn remove_stopped_container(docker: &Docker, container_name: &str) -> Result<(), Error> {
+    let state = match docker.inspect_container(container_name, None).await {
+        Ok(container) => container.state,
+        Err(e) if is_not_found(&e) => return Ok(()),
+        Err(e) => return Err(e.into()),
+    };
+    if state.and_then(|state| state.running).unwrap_or_default() {
+        return Err(Error::AlreadyRunning(container_name.to_string()));
+    }
+    docker
+        .remove_container(container_name, None::<RemoveContainerOptions>)
+        .await?;
     Ok(())
 }
 
@@ -132,7 +263,7 @@ fn get_container_args(cmd: &Cmd) -> Vec<String> {
     .collect()
 }
 
-fn get_image_name(cmd: &Cmd) -> String {
+fn get_image_name(cmd: &Cmd, print: &Print) -> String {
     // this can be overriden with the `-t` flag
     let mut image_tag = match cmd.network {
         Network::Pubnet => "latest",
@@ -141,7 +272,9 @@ fn get_image_name(cmd: &Cmd) -> String {
     };
 
     if let Some(image_override) = &cmd.image_tag_override {
-        println!("Overriding docker image tag to use '{image_override}' instead of '{image_tag}'");
+        print.infoln(format!(
+            "Overriding docker image tag to use '{image_override}' instead of '{image_tag}'"
+        ));
         image_tag = image_override;
     }
 
@@ -152,14 +285,12 @@ fn get_image_name(cmd: &Cmd) -> String {
 fn get_port_mapping(cmd: &Cmd) -> HashMap<String, Option<Vec<PortBinding>>> {
     let mut port_mapping_hash = HashMap::new();
     for port_mapping in &cmd.ports_mapping {
-        let ports_vec: Vec<&str> = port_mapping.split(':').collect();
-        let from_port = ports_vec[0];
-        let to_port = ports_vec[1];
+        let (host_ip, from_port, to_port) = split_port_mapping(port_mapping);
 
         port_mapping_hash.insert(
             format!("{to_port}/tcp"),
             Some(vec![PortBinding {
-                host_ip: None,
+                host_ip: host_ip.map(str::to_string),
                 host_port: Some(from_port.to_string()),
             }]),
         );
@@ -168,6 +299,14 @@ fn get_port_mapping(cmd: &Cmd) -> HashMap<String, Option<Vec<PortBinding>>> {
     port_mapping_hash
 }
 
+/// Host IP, host port and container port of a `[HOST_IP:]HOST_PORT:CONTAINER_PORT` mapping.
+pub fn split_port_mapping(mapping: &str) -> (Option<&str>, &str, &str) {
+    let mut parts = mapping.rsplitn(3, ':');
+    let container_port = parts.next().unwrap_or_default();
+    let host_port = parts.next().unwrap_or(container_port);
+    (parts.next(), host_port, container_port)
+}
+
 fn get_protocol_version_arg(cmd: &Cmd) -> String {
     if cmd.network == Network::Local && cmd.protocol_version.is_some() {
         let version = cmd.protocol_version.as_ref().unwrap();
@@ -185,3 +324,18 @@ fn get_limits_arg(cmd: &Cmd) -> String {
         String::new()
     }
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn port_mappings() {
+        assert_eq!(split_port_mapping("8000:8000"), (None, "8000", "8000"));
+        assert_eq!(
+            split_port_mapping("127.0.0.1:8001:8000"),
+            (Some("127.0.0.1"), "8001", "8000")
+        );
+        assert_eq!(split_port_mapping("8000"), (None, "8000", "8000"));
+    }
+}
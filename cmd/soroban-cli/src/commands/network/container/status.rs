@@ -0,0 +1,65 @@
+use crate::{
+    commands::{
+        global,
+        network::container::shared::{
+            connect_to_docker, is_not_found, Error as ConnectionError, Network, DOCKER_HOST_HELP,
+        },
+    },
+    print::Print,
+};
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    ConnectionError(#[from] ConnectionError),
+
+    #[error("⛔ ️Failed to inspect container: {0}")]
+    BollardErr(#[from] bollard::errors::Error),
+
+    #[error("Container {0} is not running, start it with `stellar network container start`")]
+    NotFound(String),
+}
+
+#[derive(Debug, clap::Parser, Clone)]
+pub struct Cmd {
+    /// Network to check
+    pub network: Network,
+
+    #[arg(short = 'd', long, help = DOCKER_HOST_HELP, env = "DOCKER_HOST")]
+    pub docker_host: Option<String>,
+}
+
+impl Cmd {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
+        let container_name = self.network.container_name();
+        let docker = connect_to_docker(&self.docker_host, &print).await?;
+        let container = match docker.inspect_container(&container_name, None).await {
+            Ok(container) => container,
+            Err(e) if is_not_found(&e) => return Err(Error::NotFound(container_name)),
+            Err(e) => return Err(e.into()),
+        };
+        let state = container.state.unwrap_or_default();
+        let status = state
+            .status
+            .map_or_else(|| "unknown".to_string(), ToString::to_string);
+        let health = state.health.unwrap_or_default();
+
+        println!("Container: {container_name}");
+        println!("Status: {status}");
+        if let Some(health_status) = health.status {
+            println!("Health: {health_status}");
+        }
+        println!("Restarts: {}", container.restart_count.unwrap_or_default());
+        println!("Data volume: {}", self.network.volume_name());
+        // The output of the most recent health check says why the container is unhealthy
+        if let Some(check) = health.log.and_then(|mut log| log.pop()) {
+            if check.exit_code.is_some_and(|code| code != 0) {
+                if let Some(output) = check.output.filter(|o| !o.trim().is_empty()) {
+                    println!("Last health check: {}", output.trim());
+                }
+            }
+        }
+        Ok(())
+    }
+}
@@ -1,11 +1,22 @@
-use crate::commands::network::container::shared::{
-    connect_to_docker, Error as ConnectionError, Network, DOCKER_HOST_HELP,
+use bollard::container::RemoveContainerOptions;
+
+use crate::{
+    commands::{
+        global,
+        network::container::shared::{
+            connect_to_docker, Error as ConnectionError, Network, DOCKER_HOST_HELP,
+        },
+    },
+    print::Print,
 };
 
 #[derive(thiserror::Error, Debug)]
 pub enum Error {
     #[error("Failed to stop container: {0}")]
     StopContainerError(#[from] ConnectionError),
+
+    #[error("Failed to stop container: {0}")]
+    BollardErr(#[from] bollard::errors::Error),
 }
 
 #[derive(Debug, clap::Parser, Clone)]
@@ -18,12 +29,24 @@ pub struct Cmd {
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
-        let container_name = format!("stellar-{}", self.network);
-        let docker = connect_to_docker(&self.docker_host).await?;
-        println!("ℹ️  Stopping container: {container_name}");
-        docker.stop_container(&container_name, None).await.unwrap();
-        println!("✅ Container stopped: {container_name}");
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
+        let container_name = self.network.container_name();
+        let docker = connect_to_docker(&self.docker_host, &print).await?;
+        print.infoln(format!("Stopping container: {container_name}"));
+        match docker.stop_container(&container_name, None).await {
+            // 304 means the container was already stopped, it still needs removing
+            Ok(())
+            | Err(bollard::errors::Error::DockerResponseServerError {
+                status_code: 304, ..
+            }) => {}
+            Err(e) => return Err(e.into()),
+        }
+        // The chain data volume is kept, so the next start resumes where this one left off
+        docker
+            .remove_container(&container_name, None::<RemoveContainerOptions>)
+            .await?;
+        print.checkln(format!("Container stopped: {container_name}"));
         Ok(())
     }
 }
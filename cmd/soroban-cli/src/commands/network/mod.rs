@@ -1,16 +1,21 @@
-use std::str::FromStr;
+use std::{future::Future, ops::Deref, str::FromStr, sync::Mutex, time::Duration};
 
 use clap::{arg, Parser};
+use jsonrpsee_core::{client::ClientT, rpc_params};
+use jsonrpsee_http_client::HttpClientBuilder;
+use rand::Rng;
 use serde::{Deserialize, Serialize};
 use serde_json::Value;
+use soroban_env_host::meta;
 use stellar_strkey::ed25519::PublicKey;
 
 use crate::{
     commands::HEADING_RPC,
+    print::Print,
     rpc::{self, Client},
 };
 
-use super::config::locator;
+use super::{config::locator, global};
 
 pub const LOCAL_NETWORK_PASSPHRASE: &str = "Standalone Network ; February 2017";
 
@@ -36,14 +41,14 @@ pub enum Cmd {
     /// stellar network start <NETWORK> [OPTIONS]
     ///
     /// By default, when starting a testnet container, without any optional arguments, it will run the equivalent of the following docker command:
-    /// docker run --rm -p 8000:8000 --name stellar stellar/quickstart:testing --testnet --enable-soroban-rpc
+    /// docker run --restart unless-stopped -v stellar-testnet-data:/opt/stellar -p 8000:8000 --name stellar-testnet stellar/quickstart:testing --testnet --enable-soroban-rpc
     Start(container::StartCmd),
     /// ⚠️ Deprecated: use `stellar container stop` instead
     ///
     /// Stop a network started with `network start`. For example, if you ran `stellar network start local`, you can use `stellar network stop local` to stop it.
     Stop(container::StopCmd),
 
-    /// Commands to start, stop and get logs for a quickstart container
+    /// Commands to start, stop, check and get logs for a quickstart container
     #[command(subcommand)]
     Container(container::Cmd),
 }
@@ -89,25 +94,36 @@ pub enum Error {
     InproperResponse(String),
     #[error("Currently not supported on windows. Please visit:\n{0}")]
     WindowsNotSupported(String),
+    #[error("none of the RPC endpoints {0} is reachable")]
+    NoRpcEndpointAvailable(String),
+    #[error("RPC server {rpc_url} did not answer within {timeout} seconds")]
+    RpcTimeout { rpc_url: String, timeout: u64 },
+    #[error("RPC server {rpc_url} runs protocol {rpc}, but this CLI was built for protocol {cli}")]
+    ProtocolVersionMismatch { rpc_url: String, rpc: u32, cli: u32 },
 }
 
 impl Cmd {
-    pub async fn run(&self) -> Result<(), Error> {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
         match self {
             Cmd::Add(cmd) => cmd.run()?,
             Cmd::Rm(new) => new.run()?,
             Cmd::Ls(cmd) => cmd.run()?,
-            Cmd::Container(cmd) => cmd.run().await?,
+            Cmd::Container(cmd) => cmd.run(global_args).await?,
 
             // TODO Remove this once `network start` is removed
             Cmd::Start(cmd) => {
-                eprintln!("⚠️ Warning: `network start` has been deprecated. Use `network container start` instead");
-                cmd.run().await?;
+                print.warnln(
+                    "`network start` has been deprecated. Use `network container start` instead",
+                );
+                cmd.run(global_args).await?;
             }
             // TODO Remove this once `network stop` is removed
             Cmd::Stop(cmd) => {
-                println!("⚠️ Warning: `network stop` has been deprecated. Use `network container stop` instead");
-                cmd.run().await?;
+                print.warnln(
+                    "`network stop` has been deprecated. Use `network container stop` instead",
+                );
+                cmd.run(global_args).await?;
             }
         };
         Ok(())
@@ -143,10 +159,33 @@ pub struct Args {
         help_heading = HEADING_RPC,
     )]
     pub network: Option<String>,
+    /// Fail instead of warning when the RPC server runs a different protocol version than this CLI was built for
+    #[arg(long, env = "STELLAR_STRICT_VERSION", help_heading = HEADING_RPC)]
+    pub strict_version: bool,
+    /// Times a failed or timed out RPC call is retried, with jittered exponential backoff. Default: `2`
+    #[arg(long, env = "STELLAR_RPC_RETRIES", help_heading = HEADING_RPC)]
+    pub rpc_retries: Option<u32>,
+    /// Seconds an RPC call may take before it counts as failed. Default: `10`
+    #[arg(long, env = "STELLAR_RPC_TIMEOUT", help_heading = HEADING_RPC)]
+    pub rpc_timeout: Option<u64>,
 }
 
 impl Args {
     pub fn get(&self, locator: &locator::Args) -> Result<Network, Error> {
+        let default = Retry::default();
+        Ok(Network {
+            strict_version: self.strict_version,
+            retry: Retry {
+                retries: self.rpc_retries.unwrap_or(default.retries),
+                timeout: self
+                    .rpc_timeout
+                    .map_or(default.timeout, Duration::from_secs),
+            },
+            ..self.resolve(locator)?
+        })
+    }
+
+    fn resolve(&self, locator: &locator::Args) -> Result<Network, Error> {
         if let Some(name) = self.network.as_deref() {
             if let Ok(network) = locator.read_network(name) {
                 return Ok(network);
@@ -158,6 +197,8 @@ impl Args {
             Ok(Network {
                 rpc_url,
                 network_passphrase,
+                strict_version: false,
+                retry: Retry::default(),
             })
         } else {
             Err(Error::Network)
@@ -168,7 +209,7 @@ impl Args {
 #[derive(Debug, clap::Args, Serialize, Deserialize, Clone)]
 #[group(skip)]
 pub struct Network {
-    /// RPC server endpoint
+    /// RPC server endpoint, or a comma separated list of endpoints to fail over between
     #[arg(
         long = "rpc-url",
         env = "STELLAR_RPC_URL",
@@ -182,15 +223,93 @@ pub struct Network {
             help_heading = HEADING_RPC,
         )]
     pub network_passphrase: String,
+    /// Fail instead of warning when the RPC server runs a different protocol version, from
+    /// `--strict-version`
+    #[arg(skip)]
+    #[serde(skip)]
+    pub strict_version: bool,
+    /// Retries and timeout of RPC calls, from `--rpc-retries` and `--rpc-timeout`
+    #[arg(skip)]
+    #[serde(skip)]
+    pub retry: Retry,
 }
 
 impl Network {
-    pub async fn helper_url(&self, addr: &str) -> Result<http::Uri, Error> {
+    /// Client for the RPC server. The RPC URL can be a comma separated list of endpoints, in
+    /// which case the first one answering `getNetwork` is used. Each endpoint is asked as often as
+    /// `retry` allows before failing over to the next. The protocol version of the endpoint is
+    /// checked with `check_protocol_version`, warning about a mismatch with `print`.
+    pub async fn rpc_client(&self, print: &Print) -> Result<RpcClient, Error> {
+        for url in self.rpc_urls() {
+            let client = Client::new(url)?;
+            match self.retry.call(url, || client.get_network()).await {
+                Ok(_) => {
+                    tracing::debug!("using RPC endpoint {url}");
+                    self.check_protocol_version(url, print).await?;
+                    return Ok(RpcClient {
+                        client,
+                        url: url.to_string(),
+                        retry: self.retry,
+                    });
+                }
+                Err(e) => tracing::warn!("RPC endpoint {url} is unavailable: {e}"),
+            }
+        }
+        Err(Error::NoRpcEndpointAvailable(self.rpc_url.clone()))
+    }
+
+    /// Endpoints listed in the RPC URL, in the order they are tried.
+    pub fn rpc_urls(&self) -> impl Iterator<Item = &str> {
+        self.rpc_url
+            .split(',')
+            .map(str::trim)
+            .filter(|url| !url.is_empty())
+    }
+
+    /// Compare the protocol version reported by the RPC server's `getVersionInfo` with the one
+    /// this CLI was built for, warning about a mismatch or, with `--strict-version`, failing. Each
+    /// endpoint is checked once per run, and servers that cannot be asked are not checked at all.
+    pub async fn check_protocol_version(&self, rpc_url: &str, print: &Print) -> Result<(), Error> {
+        static CHECKED: Mutex<Vec<String>> = Mutex::new(Vec::new());
+        {
+            let mut checked = CHECKED.lock().unwrap();
+            if checked.iter().any(|checked| checked == rpc_url) {
+                return Ok(());
+            }
+            checked.push(rpc_url.to_string());
+        }
+        let Some(rpc) = rpc_protocol_version(rpc_url, self.retry.timeout).await else {
+            return Ok(());
+        };
+        let cli = meta::get_ledger_protocol_version(meta::INTERFACE_VERSION);
+        if rpc == cli {
+            return Ok(());
+        }
+        let mismatch = Error::ProtocolVersionMismatch {
+            rpc_url: rpc_url.to_string(),
+            rpc,
+            cli,
+        };
+        if self.strict_version {
+            return Err(mismatch);
+        }
+        let upgrade = if rpc > cli {
+            "the CLI"
+        } else {
+            "the RPC server"
+        };
+        print.warnln(format!(
+            "{mismatch}. Transactions may fail to encode or decode, upgrade {upgrade} or pass --strict-version to stop on this"
+        ));
+        Ok(())
+    }
+
+    pub async fn helper_url(&self, addr: &str, print: &Print) -> Result<http::Uri, Error> {
         use http::Uri;
         tracing::debug!("address {addr:?}");
-        let rpc_uri = Uri::from_str(&self.rpc_url)
-            .map_err(|_| Error::InvalidUrl(self.rpc_url.to_string()))?;
+        let client = self.rpc_client(print).await?;
         if self.network_passphrase.as_str() == LOCAL_NETWORK_PASSPHRASE {
+            let rpc_uri = client.uri()?;
             let auth = rpc_uri.authority().unwrap().clone();
             let scheme = rpc_uri.scheme_str().unwrap();
             Ok(Uri::builder()
@@ -199,10 +318,9 @@ impl Network {
                 .path_and_query(format!("/friendbot?addr={addr}"))
                 .build()?)
         } else {
-            let client = Client::new(&self.rpc_url)?;
-            let network = client.get_network().await?;
+            let network = client.retry(|| client.get_network()).await?;
             tracing::debug!("network {network:?}");
-            let uri = client.friendbot_url().await?;
+            let uri = client.retry(|| client.friendbot_url()).await?;
             tracing::debug!("URI {uri:?}");
             Uri::from_str(&format!("{uri}?addr={addr}")).map_err(|e| {
                 tracing::error!("{e}");
@@ -212,8 +330,8 @@ impl Network {
     }
 
     #[allow(clippy::similar_names)]
-    pub async fn fund_address(&self, addr: &PublicKey) -> Result<(), Error> {
-        let uri = self.helper_url(&addr.to_string()).await?;
+    pub async fn fund_address(&self, addr: &PublicKey, print: &Print) -> Result<(), Error> {
+        let uri = self.helper_url(&addr.to_string(), print).await?;
         tracing::debug!("URL {uri:?}");
         let response = match uri.scheme_str() {
             Some("http") => hyper::Client::new().get(uri.clone()).await?,
@@ -234,17 +352,13 @@ impl Network {
         tracing::debug!("{res:#?}");
         if let Some(detail) = res.get("detail").and_then(Value::as_str) {
             if detail.contains("createAccountAlreadyExist") {
-                eprintln!("Account already exists");
+                print.infoln("Account already exists");
             }
         } else if res.get("successful").is_none() {
             return Err(Error::InproperResponse(res.to_string()));
         }
         Ok(())
     }
-
-    pub fn rpc_uri(&self) -> Result<http::Uri, Error> {
-        http::Uri::from_str(&self.rpc_url).map_err(|_| Error::InvalidUrl(self.rpc_url.to_string()))
-    }
 }
 
 impl Network {
@@ -252,6 +366,194 @@ impl Network {
         Network {
             rpc_url: "https://rpc-futurenet.stellar.org:443".to_owned(),
             network_passphrase: "Test SDF Future Network ; October 2022".to_owned(),
+            strict_version: false,
+            retry: Retry::default(),
         }
     }
 }
+
+/// Client of the RPC endpoint picked by `Network::rpc_client`. Calls made through `retry` are
+/// retried and timed out as set with `--rpc-retries` and `--rpc-timeout`. Sending a transaction
+/// is not retried, as sending it again can fail as a duplicate even though the first try landed.
+pub struct RpcClient {
+    client: Client,
+    /// The endpoint that answered, out of those listed in the RPC URL
+    pub url: String,
+    retry: Retry,
+}
+
+impl RpcClient {
+    /// Make an idempotent call to the endpoint, e.g. `client.retry(|| client.get_account(id))`.
+    pub async fn retry<T, E, F, Fut>(&self, call: F) -> Result<T, Error>
+    where
+        F: FnMut() -> Fut,
+        Fut: Future<Output = Result<T, E>>,
+        Error: From<E>,
+    {
+        self.retry.call(&self.url, call).await
+    }
+
+    /// URI of the endpoint.
+    pub fn uri(&self) -> Result<http::Uri, Error> {
+        http::Uri::from_str(&self.url).map_err(|_| Error::InvalidUrl(self.url.clone()))
+    }
+}
+
+impl Deref for RpcClient {
+    type Target = Client;
+
+    fn deref(&self) -> &Client {
+        &self.client
+    }
+}
+
+/// Protocol version reported by `getVersionInfo`, which names the field `protocol_version` before
+/// RPC 22 and `protocolVersion` after.
+async fn rpc_protocol_version(rpc_url: &str, timeout: Duration) -> Option<u32> {
+    let result: Value = HttpClientBuilder::default()
+        .request_timeout(timeout)
+        .build(rpc_url)
+        .ok()?
+        .request("getVersionInfo", rpc_params![])
+        .await
+        .map_err(|e| tracing::debug!("getVersionInfo from {rpc_url} failed: {e}"))
+        .ok()?;
+    let version = result
+        .get("protocolVersion")
+        .or_else(|| result.get("protocol_version"))?;
+    version.as_u64()?.try_into().ok()
+}
+
+/// Retries and per-attempt timeout of RPC calls, set with `--rpc-retries` and `--rpc-timeout` in
+/// seconds.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct Retry {
+    pub retries: u32,
+    pub timeout: Duration,
+}
+
+impl Default for Retry {
+    fn default() -> Self {
+        Retry {
+            retries: 2,
+            timeout: Duration::from_secs(10),
+        }
+    }
+}
+
+impl Retry {
+    /// Make an RPC call to `rpc_url`, giving up on an attempt after the timeout and retrying
+    /// failed attempts with jittered exponential backoff.
+    pub async fn call<T, E, F, Fut>(&self, rpc_url: &str, mut call: F) -> Result<T, Error>
+    where
+        F: FnMut() -> Fut,
+        Fut: Future<Output = Result<T, E>>,
+        Error: From<E>,
+    {
+        let mut attempt = 0;
+        loop {
+            let error = match tokio::time::timeout(self.timeout, call()).await {
+                Ok(Ok(value)) => {
+                    tracing::debug!("RPC call answered by {rpc_url}");
+                    return Ok(value);
+                }
+                Ok(Err(e)) => Error::from(e),
+                Err(_) => Error::RpcTimeout {
+                    rpc_url: rpc_url.to_string(),
+                    timeout: self.timeout.as_secs(),
+                },
+            };
+            if attempt >= self.retries {
+                return Err(error);
+            }
+            attempt += 1;
+            tracing::warn!(
+                "RPC call to {rpc_url} failed (attempt {attempt} of {}): {error}",
+                self.retries + 1
+            );
+            tokio::time::sleep(backoff(attempt)).await;
+        }
+    }
+}
+
+/// Half a second doubled for every retry, plus up to as much again at random so that scripts
+/// retrying at the same time spread out.
+fn backoff(attempt: u32) -> Duration {
+    let base = 500 * 2u64.pow((attempt - 1).min(6));
+    Duration::from_millis(base + rand::thread_rng().gen_range(0..=base))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn retry_settings_from_args() {
+        let args = Args {
+            rpc_url: Some("http://localhost:8000/rpc".to_string()),
+            network_passphrase: Some(LOCAL_NETWORK_PASSPHRASE.to_string()),
+            ..Args::default()
+        };
+        let locator = locator::Args::default();
+        assert_eq!(args.get(&locator).unwrap().retry, Retry::default());
+        let args = Args {
+            rpc_retries: Some(5),
+            rpc_timeout: Some(3),
+            ..args
+        };
+        assert_eq!(
+            args.get(&locator).unwrap().retry,
+            Retry {
+                retries: 5,
+                timeout: Duration::from_secs(3),
+            }
+        );
+    }
+
+    #[tokio::test]
+    async fn retries_failed_calls() {
+        let retry = Retry {
+            retries: 1,
+            timeout: Duration::from_secs(1),
+        };
+        let mut calls = 0;
+        let result = retry
+            .call("http://localhost:8000", || {
+                calls += 1;
+                let result = if calls == 1 {
+                    Err(Error::Network)
+                } else {
+                    Ok(calls)
+                };
+                async move { result }
+            })
+            .await;
+        assert_eq!(result.unwrap(), 2);
+
+        let retry = Retry {
+            retries: 0,
+            ..retry
+        };
+        let result = retry
+            .call("http://localhost:8000", || async {
+                Err::<(), _>(Error::Network)
+            })
+            .await;
+        assert!(matches!(result, Err(Error::Network)));
+    }
+
+    #[tokio::test]
+    async fn times_out_slow_calls() {
+        let retry = Retry {
+            retries: 0,
+            timeout: Duration::from_millis(10),
+        };
+        let result = retry
+            .call("http://localhost:8000", || async {
+                tokio::time::sleep(Duration::from_secs(1)).await;
+                Ok::<_, Error>(())
+            })
+            .await;
+        assert!(matches!(result, Err(Error::RpcTimeout { .. })));
+    }
+}
@@ -0,0 +1,341 @@
+use std::{
+    collections::BTreeMap,
+    fs, io,
+    path::{Path, PathBuf},
+    process::Command,
+};
+
+use clap::{arg, command, Parser};
+use serde::Deserialize;
+use soroban_env_host::xdr::ScSpecEntry;
+use soroban_spec_tools::contract::{self, Spec};
+
+use crate::{commands::global, print::Print, utils::find_config_dir};
+
+pub const LONG_ABOUT: &str = "\
+Check contracts against the deployment policy of the project
+
+The policy is read from policy.toml in the project's .stellar directory, e.g.:
+  max_wasm_size = 65536
+  require_docs = true
+  require_optimized = true
+  forbidden_host_functions = [\"x._\"]
+
+  [networks]
+  main = [\"mainnet\"]
+  \"release/*\" = [\"testnet\"]
+
+Run it before deploying or invoking, in CI, or as a git pre-commit hook:
+  echo \"stellar policy check --pre-commit\" >> .git/hooks/pre-commit";
+
+const DEFAULT_WASM_DIR: &str = "target/wasm32-unknown-unknown/release";
+
+#[derive(Parser, Debug, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// Path to a wasm binary to check, can be repeated. Defaults to every contract in target/wasm32-unknown-unknown/release, using its `.optimized.wasm` when there is one
+    #[arg(long)]
+    pub wasm: Vec<PathBuf>,
+    /// Path to the policy file, defaults to policy.toml in the project's .stellar directory
+    #[arg(long)]
+    pub policy: Option<PathBuf>,
+    /// Name of the network the contracts are going to be deployed to, checked against the current git branch
+    #[arg(long, env = "STELLAR_NETWORK")]
+    pub network: Option<String>,
+    /// Only report violations, and pass when the project has no policy file
+    #[arg(long)]
+    pub pre_commit: bool,
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error("no policy file found, create policy.toml in the project's .stellar directory or pass --policy")]
+    NoPolicy,
+    #[error("reading policy file {0:?}: {1}")]
+    CannotReadPolicy(PathBuf, io::Error),
+    #[error("parsing policy file {0:?}: {1}")]
+    CannotParsePolicy(PathBuf, toml::de::Error),
+    #[error("no wasm files found in {DEFAULT_WASM_DIR}, build the contracts or pass --wasm")]
+    NoWasm,
+    #[error("reading wasm file {0:?}: {1}")]
+    CannotReadWasm(PathBuf, io::Error),
+    #[error("parsing wasm file {0:?}: {1}")]
+    CannotParseWasm(PathBuf, wasmparser::BinaryReaderError),
+    #[error("reading contract spec of {0:?}: {1}")]
+    Spec(PathBuf, contract::Error),
+    #[error("cannot determine the current git branch: {0}")]
+    Branch(String),
+    #[error("found {0} policy violations")]
+    Violations(usize),
+}
+
+/// Deployment policy of a project.
+#[derive(Deserialize, Debug, Default, Clone, PartialEq, Eq)]
+#[serde(deny_unknown_fields)]
+pub struct Policy {
+    /// Largest wasm file allowed, in bytes
+    pub max_wasm_size: Option<u64>,
+    /// Every function in the contract spec must have a doc comment
+    #[serde(default)]
+    pub require_docs: bool,
+    /// The wasm must be the output of `contract optimize`, which names it `*.optimized.wasm`
+    #[serde(default)]
+    pub require_optimized: bool,
+    /// Host functions the contract must not import, written as `module.name`
+    #[serde(default)]
+    pub forbidden_host_functions: Vec<String>,
+    /// Networks that may be deployed to from a git branch, by branch name or `prefix*` pattern.
+    /// Branches that match no pattern may deploy anywhere.
+    #[serde(default)]
+    pub networks: BTreeMap<String, Vec<String>>,
+}
+
+impl Cmd {
+    pub fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
+        let Some(policy) = self.read_policy()? else {
+            if self.pre_commit {
+                return Ok(());
+            }
+            return Err(Error::NoPolicy);
+        };
+
+        let mut violations = 0;
+        for path in self.wasm_paths()? {
+            let bytes = fs::read(&path).map_err(|e| Error::CannotReadWasm(path.clone(), e))?;
+            let found = policy.check_wasm(&path, &bytes)?;
+            if found.is_empty() && !self.pre_commit {
+                print.checkln(format!("{} satisfies the policy", path.display()));
+            }
+            for violation in &found {
+                print.errorln(format!("{}: {violation}", path.display()));
+            }
+            violations += found.len();
+        }
+
+        if let Some(network) = self.network.as_deref() {
+            if !policy.networks.is_empty() {
+                let branch = current_branch()?;
+                match policy.check_network(&branch, network) {
+                    Ok(()) if !self.pre_commit => {
+                        print.checkln(format!("Network {network} is allowed from branch {branch}"))
+                    }
+                    Ok(()) => {}
+                    Err(violation) => {
+                        print.errorln(violation);
+                        violations += 1;
+                    }
+                }
+            }
+        }
+
+        if violations > 0 {
+            return Err(Error::Violations(violations));
+        }
+        Ok(())
+    }
+
+    fn read_policy(&self) -> Result<Option<Policy>, Error> {
+        let path = match &self.policy {
+            Some(path) => path.clone(),
+            None => {
+                let Ok(config_dir) = std::env::current_dir().and_then(find_config_dir) else {
+                    return Ok(None);
+                };
+                let path = config_dir.join("policy.toml");
+                if !path.exists() {
+                    return Ok(None);
+                }
+                path
+            }
+        };
+        let contents =
+            fs::read_to_string(&path).map_err(|e| Error::CannotReadPolicy(path.clone(), e))?;
+        toml::from_str(&contents)
+            .map(Some)
+            .map_err(|e| Error::CannotParsePolicy(path, e))
+    }
+
+    fn wasm_paths(&self) -> Result<Vec<PathBuf>, Error> {
+        if !self.wasm.is_empty() {
+            return Ok(self.wasm.clone());
+        }
+        let mut paths = fs::read_dir(DEFAULT_WASM_DIR)
+            .map_err(|_| Error::NoWasm)?
+            .filter_map(|entry| Some(entry.ok()?.path()))
+            .filter(|path| {
+                path.extension()
+                    .is_some_and(|extension| extension == "wasm")
+            })
+            .collect::<Vec<_>>();
+        if paths.is_empty() {
+            return Err(Error::NoWasm);
+        }
+        paths.sort();
+        Ok(deployed(paths))
+    }
+}
+
+/// Wasm files that would be deployed, leaving out `x.wasm` when `contract optimize` has written
+/// `x.optimized.wasm` next to it.
+fn deployed(paths: Vec<PathBuf>) -> Vec<PathBuf> {
+    paths
+        .iter()
+        .filter(|path| !paths.contains(&path.with_extension("optimized.wasm")))
+        .cloned()
+        .collect()
+}
+
+impl Policy {
+    /// Violations of the policy by a wasm file.
+    pub fn check_wasm(&self, path: &Path, bytes: &[u8]) -> Result<Vec<String>, Error> {
+        let mut violations = vec![];
+
+        if let Some(max) = self.max_wasm_size {
+            let size = bytes.len() as u64;
+            if size > max {
+                violations.push(format!(
+                    "wasm is {size} bytes, larger than the limit of {max} bytes"
+                ));
+            }
+        }
+
+        if self.require_docs {
+            let spec = Spec::new(bytes).map_err(|e| Error::Spec(path.to_path_buf(), e))?;
+            for entry in &spec.spec {
+                if let ScSpecEntry::FunctionV0(func) = entry {
+                    if func.doc.is_empty() {
+                        violations.push(format!(
+                            "function {} has no doc comment",
+                            func.name.to_utf8_string_lossy()
+                        ));
+                    }
+                }
+            }
+        }
+
+        if self.require_optimized
+            && !path
+                .file_name()
+                .is_some_and(|name| name.to_string_lossy().ends_with(".optimized.wasm"))
+        {
+            violations.push("wasm has not been optimized with `contract optimize`".to_string());
+        }
+
+        if !self.forbidden_host_functions.is_empty() {
+            let imported =
+                imports(bytes).map_err(|e| Error::CannotParseWasm(path.to_path_buf(), e))?;
+            for import in imported {
+                if self.forbidden_host_functions.contains(&import) {
+                    violations.push(format!("imports forbidden host function {import}"));
+                }
+            }
+        }
+
+        Ok(violations)
+    }
+
+    /// Check that the network may be deployed to from the branch.
+    pub fn check_network(&self, branch: &str, network: &str) -> Result<(), String> {
+        let allowed = self
+            .networks
+            .iter()
+            .filter(|(pattern, _)| branch_matches(pattern, branch))
+            .flat_map(|(_, networks)| networks)
+            .collect::<Vec<_>>();
+        if allowed.is_empty() || allowed.iter().any(|allowed| *allowed == network) {
+            return Ok(());
+        }
+        Err(format!(
+            "network {network} is not allowed from branch {branch}, allowed: {}",
+            allowed
+                .iter()
+                .map(|network| network.as_str())
+                .collect::<Vec<_>>()
+                .join(", ")
+        ))
+    }
+}
+
+fn branch_matches(pattern: &str, branch: &str) -> bool {
+    match pattern.strip_suffix('*') {
+        Some(prefix) => branch.starts_with(prefix),
+        None => pattern == branch,
+    }
+}
+
+/// Functions imported by the wasm, as `module.name`.
+fn imports(bytes: &[u8]) -> Result<Vec<String>, wasmparser::BinaryReaderError> {
+    let mut imports = vec![];
+    for payload in wasmparser::Parser::new(0).parse_all(bytes) {
+        if let wasmparser::Payload::ImportSection(section) = payload? {
+            for import in section {
+                let import = import?;
+                imports.push(format!("{}.{}", import.module, import.name));
+            }
+        }
+    }
+    Ok(imports)
+}
+
+fn current_branch() -> Result<String, Error> {
+    let output = Command::new("git")
+        .args(["rev-parse", "--abbrev-ref", "HEAD"])
+        .output()
+        .map_err(|e| Error::Branch(e.to_string()))?;
+    if !output.status.success() {
+        return Err(Error::Branch(
+            String::from_utf8_lossy(&output.stderr).trim().to_string(),
+        ));
+    }
+    Ok(String::from_utf8_lossy(&output.stdout).trim().to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn network_allowed_by_branch() {
+        let policy: Policy = toml::from_str(
+            r#"
+            [networks]
+            main = ["mainnet"]
+            "release/*" = ["testnet", "futurenet"]
+            "#,
+        )
+        .unwrap();
+        assert!(policy.check_network("main", "mainnet").is_ok());
+        assert!(policy.check_network("main", "testnet").is_err());
+        assert!(policy.check_network("release/1.0", "futurenet").is_ok());
+        assert!(policy.check_network("release/1.0", "mainnet").is_err());
+        assert!(policy.check_network("feature", "mainnet").is_ok());
+    }
+
+    #[test]
+    fn wasm_size_and_optimization() {
+        let policy = Policy {
+            max_wasm_size: Some(4),
+            require_optimized: true,
+            ..Default::default()
+        };
+        let wasm = b"\0asm\x01\0\0\0";
+        let violations = policy.check_wasm(Path::new("contract.wasm"), wasm).unwrap();
+        assert_eq!(violations.len(), 2);
+        assert!(policy
+            .check_wasm(Path::new("contract.optimized.wasm"), &wasm[..4])
+            .unwrap()
+            .is_empty());
+    }
+
+    #[test]
+    fn only_optimized_build_is_checked() {
+        let paths = ["a.optimized.wasm", "a.wasm", "b.wasm"]
+            .map(PathBuf::from)
+            .to_vec();
+        assert_eq!(
+            deployed(paths),
+            vec![PathBuf::from("a.optimized.wasm"), PathBuf::from("b.wasm")]
+        );
+    }
+}
@@ -0,0 +1,27 @@
+use clap::Parser;
+
+use super::global;
+
+pub mod check;
+
+#[derive(Debug, Parser)]
+pub enum Cmd {
+    /// Check contracts against the deployment policy of the project
+    #[command(long_about = check::LONG_ABOUT)]
+    Check(check::Cmd),
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Check(#[from] check::Error),
+}
+
+impl Cmd {
+    pub fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        match self {
+            Cmd::Check(cmd) => cmd.run(global_args)?,
+        };
+        Ok(())
+    }
+}
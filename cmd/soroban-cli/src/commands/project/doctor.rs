@@ -0,0 +1,226 @@
+use std::{
+    collections::BTreeSet,
+    fs, io,
+    path::{Path, PathBuf},
+};
+
+use cargo_metadata::{semver::Version, MetadataCommand};
+use clap::{arg, command, Parser};
+use toml_edit::{Document, Item, TomlError};
+
+use crate::{
+    commands::{config::locator, global, network},
+    print::Print,
+    rpc,
+};
+
+pub const LONG_ABOUT: &str = "\
+Check the project's soroban-sdk versions against the protocol of the network
+
+Since soroban-sdk 20 the major version of the SDK is the protocol version it targets. A contract \
+built with an SDK newer than the protocol of the network fails to deploy with an env interface \
+version mismatch. The versions are read from Cargo.lock, so the check matches what cargo builds.
+
+Pass --fix to pin soroban-sdk to the protocol version of the network in every Cargo.toml of the \
+workspace:
+  stellar project doctor --network testnet --fix";
+
+/// Oldest soroban-sdk major version that targets a released protocol
+const MIN_SDK_MAJOR: u64 = 20;
+
+const DEPENDENCY_TABLES: [&str; 3] = ["dependencies", "dev-dependencies", "build-dependencies"];
+
+#[derive(Parser, Debug, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// Path to Cargo.toml
+    #[arg(long, default_value = "Cargo.toml")]
+    pub manifest_path: PathBuf,
+    /// Pin soroban-sdk to a version compatible with the network in the workspace's manifests
+    #[arg(long)]
+    pub fix: bool,
+    #[command(flatten)]
+    pub locator: locator::Args,
+    #[command(flatten)]
+    pub network: network::Args,
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Network(#[from] network::Error),
+    #[error(transparent)]
+    Rpc(#[from] rpc::Error),
+    #[error(transparent)]
+    Metadata(#[from] cargo_metadata::Error),
+    #[error("reading manifest {0:?}: {1}")]
+    CannotReadManifest(PathBuf, io::Error),
+    #[error("parsing manifest {0:?}: {1}")]
+    CannotParseManifest(PathBuf, TomlError),
+    #[error("writing manifest {0:?}: {1}")]
+    CannotWriteManifest(PathBuf, io::Error),
+    #[error("found {0} soroban-sdk versions incompatible with protocol {1}, pin soroban-sdk = \"{1}\" or pass --fix")]
+    Incompatible(usize, u32),
+}
+
+impl Cmd {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
+        let network = self.network.get(&self.locator)?;
+        let client = network.rpc_client(&print).await?;
+        let protocol = client
+            .retry(|| client.get_network())
+            .await?
+            .protocol_version;
+        print.globeln(format!("Network protocol version {protocol}"));
+
+        let metadata = MetadataCommand::new()
+            .manifest_path(&self.manifest_path)
+            .exec()?;
+        let versions = metadata
+            .packages
+            .iter()
+            .filter(|p| p.name == "soroban-sdk")
+            .map(|p| p.version.clone())
+            .collect::<BTreeSet<_>>();
+        if versions.is_empty() {
+            print.warnln("No soroban-sdk dependency found");
+            return Ok(());
+        }
+        let mut incompatible = 0;
+        for version in &versions {
+            if compatible(version, protocol) {
+                print.checkln(format!("soroban-sdk {version} is compatible"));
+            } else {
+                print.errorln(format!(
+                    "soroban-sdk {version} does not target protocol {protocol}, use soroban-sdk {protocol}"
+                ));
+                incompatible += 1;
+            }
+        }
+        if incompatible == 0 {
+            return Ok(());
+        }
+        if !self.fix {
+            return Err(Error::Incompatible(incompatible, protocol));
+        }
+
+        let mut manifests = BTreeSet::new();
+        manifests.insert(
+            metadata
+                .workspace_root
+                .join("Cargo.toml")
+                .into_std_path_buf(),
+        );
+        for package in metadata.workspace_packages() {
+            manifests.insert(package.manifest_path.clone().into_std_path_buf());
+        }
+        for manifest in &manifests {
+            if fix_manifest(manifest, &protocol.to_string())? {
+                print.saveln(format!("Pinned soroban-sdk to {protocol} in {manifest:?}"));
+            }
+        }
+        print.infoln("Cargo.lock is updated the next time the project is built");
+        Ok(())
+    }
+}
+
+/// Whether a contract built with the soroban-sdk version can be deployed to a network running
+/// the protocol.
+pub fn compatible(version: &Version, protocol: u32) -> bool {
+    (MIN_SDK_MAJOR..=u64::from(protocol)).contains(&version.major)
+}
+
+fn fix_manifest(path: &Path, version: &str) -> Result<bool, Error> {
+    let contents =
+        fs::read_to_string(path).map_err(|e| Error::CannotReadManifest(path.to_path_buf(), e))?;
+    let mut doc = contents
+        .parse::<Document>()
+        .map_err(|e| Error::CannotParseManifest(path.to_path_buf(), e))?;
+    if !pin_sdk(&mut doc, version) {
+        return Ok(false);
+    }
+    fs::write(path, doc.to_string())
+        .map_err(|e| Error::CannotWriteManifest(path.to_path_buf(), e))?;
+    Ok(true)
+}
+
+/// Set the version requirement of every soroban-sdk dependency declared in the manifest,
+/// returning whether any was changed. Dependencies inherited with `workspace = true` are left to
+/// the workspace manifest.
+fn pin_sdk(doc: &mut Document, version: &str) -> bool {
+    let mut changed = false;
+    for table in DEPENDENCY_TABLES {
+        changed |= pin_dependency(doc.get_mut(table), version);
+    }
+    let workspace = doc
+        .get_mut("workspace")
+        .and_then(|workspace| workspace.get_mut("dependencies"));
+    changed |= pin_dependency(workspace, version);
+    changed
+}
+
+fn pin_dependency(table: Option<&mut Item>, version: &str) -> bool {
+    let Some(dependency) = table.and_then(|table| table.get_mut("soroban-sdk")) else {
+        return false;
+    };
+    let requirement = if dependency.is_str() {
+        Some(dependency)
+    } else {
+        dependency.get_mut("version")
+    };
+    let Some(value) = requirement.and_then(Item::as_value_mut) else {
+        return false;
+    };
+    if value.as_str() == Some(version) {
+        return false;
+    }
+    let decor = value.decor().clone();
+    *value = version.into();
+    *value.decor_mut() = decor;
+    true
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn sdk_compatible_with_protocol() {
+        assert!(compatible(&Version::new(20, 3, 2), 21));
+        assert!(compatible(&Version::new(21, 0, 0), 21));
+        assert!(!compatible(&Version::new(22, 0, 0), 21));
+        assert!(!compatible(&Version::new(0, 9, 2), 21));
+    }
+
+    #[test]
+    fn pins_declared_sdk() {
+        let mut doc = r#"[dependencies]
+soroban-sdk = "22.0.0"
+
+[dev-dependencies]
+soroban-sdk = { version = "22.0.0", features = ["testutils"] }
+"#
+        .parse::<Document>()
+        .unwrap();
+        assert!(pin_sdk(&mut doc, "21"));
+        assert_eq!(
+            doc.to_string(),
+            r#"[dependencies]
+soroban-sdk = "21"
+
+[dev-dependencies]
+soroban-sdk = { version = "21", features = ["testutils"] }
+"#
+        );
+        assert!(!pin_sdk(&mut doc, "21"));
+    }
+
+    #[test]
+    fn leaves_workspace_inherited_sdk() {
+        let mut doc = "[dependencies]\nsoroban-sdk = { workspace = true }\n"
+            .parse::<Document>()
+            .unwrap();
+        assert!(!pin_sdk(&mut doc, "21"));
+    }
+}
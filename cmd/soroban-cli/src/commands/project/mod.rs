@@ -0,0 +1,27 @@
+use clap::Parser;
+
+use super::global;
+
+pub mod doctor;
+
+#[derive(Debug, Parser)]
+pub enum Cmd {
+    /// Check the project's soroban-sdk versions against the protocol of the network
+    #[command(long_about = doctor::LONG_ABOUT)]
+    Doctor(doctor::Cmd),
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Doctor(#[from] doctor::Error),
+}
+
+impl Cmd {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        match self {
+            Cmd::Doctor(cmd) => cmd.run(global_args).await?,
+        };
+        Ok(())
+    }
+}
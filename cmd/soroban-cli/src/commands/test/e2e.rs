@@ -0,0 +1,366 @@
+use std::{
+    ffi::{OsStr, OsString},
+    path::PathBuf,
+    process::{Command, ExitStatus},
+    time::{Duration, Instant},
+};
+
+use clap::{arg, Parser};
+use futures_util::TryStreamExt;
+
+use crate::{
+    commands::{
+        config::{self, locator, secret::Secret},
+        contract::{build, deploy},
+        global,
+        network::{
+            self,
+            container::{
+                shared::{connect_to_docker, Error as ConnectionError, Network, DOCKER_HOST_HELP},
+                start, stop,
+            },
+            LOCAL_NETWORK_PASSPHRASE,
+        },
+        NetworkRunnable,
+    },
+    print::Print,
+    rpc,
+};
+
+const DEFAULT_PORT_MAPPING: &str = "8000:8000";
+// Chain data of the test network, wiped on every run and kept apart from the `local` network's
+const E2E_VOLUME: &str = "stellar-e2e-data";
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    Build(#[from] build::Error),
+    #[error(transparent)]
+    Start(#[from] start::Error),
+    #[error(transparent)]
+    Stop(#[from] stop::Error),
+    #[error(transparent)]
+    Connection(#[from] ConnectionError),
+    #[error("⛔ ️Failed to read container logs: {0}")]
+    Logs(#[from] bollard::errors::Error),
+    #[error(transparent)]
+    Deploy(#[from] deploy::wasm::Error),
+    #[error(transparent)]
+    Secret(#[from] config::secret::Error),
+    #[error(transparent)]
+    Network(#[from] network::Error),
+    #[error(transparent)]
+    Rpc(#[from] rpc::Error),
+    #[error("network was not ready after {0} seconds")]
+    NetworkNotReady(u64),
+    #[error("deploying {0} did not return a contract id")]
+    MissingContractId(String),
+    #[error("running test command {0:?}: {1}")]
+    TestCmd(String, std::io::Error),
+    #[error("test command exited with {0}")]
+    TestsFailed(ExitStatus),
+}
+
+#[derive(Parser, Debug, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// Path to Cargo.toml of the project whose contracts are deployed
+    #[arg(long, default_value = "Cargo.toml")]
+    pub manifest_path: PathBuf,
+    /// Package to build and deploy
+    ///
+    /// If omitted, all packages that build for crate-type cdylib are deployed.
+    #[arg(long, conflicts_with = "wasm")]
+    pub package: Option<String>,
+    /// WASM file to deploy instead of building the manifest. Can be repeated
+    #[arg(long)]
+    pub wasm: Vec<PathBuf>,
+
+    #[arg(short = 'd', long, help = DOCKER_HOST_HELP, env = "DOCKER_HOST")]
+    pub docker_host: Option<String>,
+    /// Argument to specify the HOST_PORT:CONTAINER_PORT mapping
+    #[arg(short = 'p', long, default_value = DEFAULT_PORT_MAPPING)]
+    pub ports_mapping: String,
+    /// Optional argument to override the default docker image tag
+    #[arg(short = 't', long)]
+    pub image_tag_override: Option<String>,
+    /// Optional argument to specify the protocol version of the network
+    #[arg(long)]
+    pub protocol_version: Option<String>,
+    /// Seconds to wait for the network to be ready before giving up
+    #[arg(long, default_value = "180")]
+    pub timeout: u64,
+    /// Leave the network running after the tests finish
+    #[arg(long)]
+    pub keep: bool,
+
+    /// Command that runs the tests, defaults to `cargo test`
+    #[arg(last = true, id = "TEST_CMD")]
+    pub test_cmd: Vec<OsString>,
+}
+
+impl Cmd {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        let print = Print::new(global_args);
+        let wasms = self.build(global_args, &print)?;
+
+        start::Cmd {
+            network: Network::Local,
+            docker_host: self.docker_host.clone(),
+            limits: None,
+            ports_mapping: vec![self.ports_mapping.clone()],
+            image_tag_override: self.image_tag_override.clone(),
+            protocol_version: self.protocol_version.clone(),
+            reset: true,
+            export_env: None,
+            volume: Some(E2E_VOLUME.to_string()),
+        }
+        .run(global_args)
+        .await?;
+
+        let res = self.run_tests(&wasms, global_args, &print).await;
+        if res.is_err() {
+            print.errorln("End-to-end tests failed, network logs follow");
+            if let Err(e) = self.print_logs(&print).await {
+                print.warnln(format!("Could not fetch the network logs: {e}"));
+            }
+        }
+        if self.keep {
+            print.infoln(format!("Network left running at {}", self.rpc_url()));
+        } else {
+            let stopped = stop::Cmd {
+                network: Network::Local,
+                docker_host: self.docker_host.clone(),
+            }
+            .run(global_args)
+            .await;
+            // A failure to stop must not hide why the tests failed
+            match stopped {
+                Err(e) if res.is_err() => {
+                    print.warnln(format!("Could not stop the network: {e}"));
+                }
+                stopped => stopped?,
+            }
+        }
+        res
+    }
+
+    /// Build the contracts in the manifest, unless wasm files were given explicitly.
+    fn build(
+        &self,
+        global_args: &global::Args,
+        print: &Print,
+    ) -> Result<Vec<(String, PathBuf)>, Error> {
+        if !self.wasm.is_empty() {
+            return Ok(self
+                .wasm
+                .iter()
+                .map(|wasm| {
+                    let name = wasm.file_stem().unwrap_or_default();
+                    (name.to_string_lossy().to_string(), wasm.clone())
+                })
+                .collect());
+        }
+        let cmd = build::Cmd {
+            manifest_path: self.manifest_path.clone(),
+            package: self.package.clone(),
+            profile: "release".to_string(),
+            features: None,
+            all_features: false,
+            no_default_features: false,
+            out_dir: None,
+            print_commands_only: false,
+            no_build_meta: false,
+        };
+        print.infoln("Building contracts");
+        cmd.run(global_args)?;
+        Ok(cmd.wasm_files()?)
+    }
+
+    async fn run_tests(
+        &self,
+        wasms: &[(String, PathBuf)],
+        global_args: &global::Args,
+        print: &Print,
+    ) -> Result<(), Error> {
+        let network = network::Network {
+            rpc_url: self.rpc_url(),
+            network_passphrase: LOCAL_NETWORK_PASSPHRASE.to_string(),
+            strict_version: false,
+            retry: network::Retry::default(),
+        };
+        self.wait_for_network(&network, print).await?;
+
+        let secret = Secret::from_seed(None)?;
+        let source_account = secret.private_key(None)?.to_string();
+        self.fund(&network, &secret, print).await?;
+
+        let config = config::Args {
+            network: network::Args {
+                rpc_url: Some(network.rpc_url.clone()),
+                network_passphrase: Some(network.network_passphrase.clone()),
+                network: None,
+                strict_version: false,
+                rpc_retries: None,
+                rpc_timeout: None,
+            },
+            source_account: source_account.clone(),
+            hd_path: None,
+            sign_with_cmd: None,
+            locator: locator::Args::default(),
+        };
+
+        let mut test_cmd = self.test_cmd();
+        test_cmd
+            .env("STELLAR_RPC_URL", &network.rpc_url)
+            .env("STELLAR_NETWORK_PASSPHRASE", &network.network_passphrase)
+            .env("STELLAR_ACCOUNT", &source_account);
+        for (name, wasm) in wasms {
+            print.infoln(format!("Deploying {name}"));
+            let contract_id = deploy::wasm::Cmd {
+                wasm: Some(wasm.clone()),
+                wasm_hash: None,
+                salt: None,
+                config: config.clone(),
+                fee: crate::fee::Args {
+                    yes: true,
+                    ..Default::default()
+                },
+                ignore_checks: false,
+                alias: None,
+                export_env: None,
+            }
+            .run_against_rpc_server(Some(global_args), None)
+            .await?
+            .into_result()
+            .ok_or_else(|| Error::MissingContractId(name.clone()))?;
+            print.checkln(format!("Deployed {name}: {contract_id}"));
+            test_cmd.env(contract_id_env_var(name), &contract_id);
+            if wasms.len() == 1 {
+                test_cmd.env("STELLAR_CONTRACT_ID", &contract_id);
+            }
+        }
+
+        let cmd_str = format!(
+            "{} {}",
+            test_cmd.get_program().to_string_lossy(),
+            test_cmd
+                .get_args()
+                .map(OsStr::to_string_lossy)
+                .collect::<Vec<_>>()
+                .join(" ")
+        );
+        print.infoln(format!("Running {cmd_str}"));
+        let status = test_cmd.status().map_err(|e| Error::TestCmd(cmd_str, e))?;
+        if !status.success() {
+            return Err(Error::TestsFailed(status));
+        }
+        print.checkln("End-to-end tests passed");
+        Ok(())
+    }
+
+    async fn wait_for_network(
+        &self,
+        network: &network::Network,
+        print: &Print,
+    ) -> Result<(), Error> {
+        print.infoln("Waiting for the network to be ready");
+        let client = rpc::Client::new(&network.rpc_url)?;
+        let deadline = Instant::now() + Duration::from_secs(self.timeout);
+        // A hung connection must not keep the loop waiting past the deadline
+        while !matches!(
+            tokio::time::timeout(Duration::from_secs(5), client.get_network()).await,
+            Ok(Ok(_))
+        ) {
+            if Instant::now() > deadline {
+                return Err(Error::NetworkNotReady(self.timeout));
+            }
+            tokio::time::sleep(Duration::from_secs(1)).await;
+        }
+        Ok(())
+    }
+
+    /// Friendbot can come up after the RPC server, so keep trying until the timeout.
+    async fn fund(
+        &self,
+        network: &network::Network,
+        secret: &Secret,
+        print: &Print,
+    ) -> Result<(), Error> {
+        let address = secret.public_key(None)?;
+        let deadline = Instant::now() + Duration::from_secs(self.timeout);
+        loop {
+            match network.fund_address(&address, print).await {
+                Ok(()) => return Ok(()),
+                Err(e) if Instant::now() > deadline => return Err(e.into()),
+                Err(e) => tracing::debug!("funding {address} failed, retrying: {e}"),
+            }
+            tokio::time::sleep(Duration::from_secs(1)).await;
+        }
+    }
+
+    async fn print_logs(&self, print: &Print) -> Result<(), Error> {
+        let docker = connect_to_docker(&self.docker_host, print).await?;
+        let logs_stream = &mut docker.logs(
+            &format!("stellar-{}", Network::Local),
+            Some(bollard::container::LogsOptions {
+                stdout: true,
+                stderr: true,
+                tail: "all",
+                ..Default::default()
+            }),
+        );
+        while let Some(log) = logs_stream.try_next().await? {
+            print.print(log);
+        }
+        Ok(())
+    }
+
+    fn rpc_url(&self) -> String {
+        let (_, host_port, _) = start::split_port_mapping(&self.ports_mapping);
+        format!("http://localhost:{host_port}/soroban/rpc")
+    }
+
+    fn test_cmd(&self) -> Command {
+        let mut args = self.test_cmd.iter();
+        let mut cmd = Command::new(args.next().map_or_else(|| "cargo".into(), Clone::clone));
+        if self.test_cmd.is_empty() {
+            cmd.arg("test");
+        }
+        cmd.args(args);
+        cmd
+    }
+}
+
+/// Environment variable holding the id of the contract deployed for a package, e.g.
+/// `STELLAR_CONTRACT_ID_HELLO_WORLD` for `hello-world`.
+fn contract_id_env_var(name: &str) -> String {
+    let name: String = name
+        .chars()
+        .map(|c| {
+            if c.is_ascii_alphanumeric() {
+                c.to_ascii_uppercase()
+            } else {
+                '_'
+            }
+        })
+        .collect();
+    format!("STELLAR_CONTRACT_ID_{name}")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn contract_id_env_var_names() {
+        assert_eq!(
+            contract_id_env_var("hello-world"),
+            "STELLAR_CONTRACT_ID_HELLO_WORLD"
+        );
+        assert_eq!(
+            contract_id_env_var("soroban_token"),
+            "STELLAR_CONTRACT_ID_SOROBAN_TOKEN"
+        );
+    }
+}
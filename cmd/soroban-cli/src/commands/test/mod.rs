@@ -0,0 +1,32 @@
+use crate::commands::global;
+
+pub mod e2e;
+
+#[derive(Debug, clap::Subcommand)]
+pub enum Cmd {
+    /// Run end-to-end tests against an ephemeral local network
+    ///
+    /// Starts a local network in a container, builds and deploys the contracts
+    /// in the cargo manifest, runs the test command with the network details
+    /// and deployed contract ids in its environment, then stops the network.
+    ///
+    /// stellar test e2e [OPTIONS] [-- <TEST_CMD>...]
+    ///
+    /// The test command defaults to `cargo test`.
+    E2e(e2e::Cmd),
+}
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    E2e(#[from] e2e::Error),
+}
+
+impl Cmd {
+    pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
+        match &self {
+            Cmd::E2e(cmd) => cmd.run(global_args).await?,
+        }
+        Ok(())
+    }
+}
@@ -0,0 +1,156 @@
+use clap::{arg, command, ValueEnum};
+use jsonrpsee_core::{client::ClientT, rpc_params};
+use jsonrpsee_http_client::HttpClientBuilder;
+use serde_json::Value;
+
+use crate::{
+    commands::{config::locator, keys, network},
+    xdr::{
+        self, FeeBumpTransaction, FeeBumpTransactionEnvelope, FeeBumpTransactionExt,
+        FeeBumpTransactionInnerTx, Limits, MuxedAccount, SorobanTransactionData,
+        TransactionEnvelope, TransactionExt, TransactionV1Envelope, Uint256, WriteXdr,
+    },
+};
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error(transparent)]
+    XdrArgs(#[from] super::xdr::Error),
+    #[error(transparent)]
+    Xdr(#[from] xdr::Error),
+    #[error(transparent)]
+    Network(#[from] network::Error),
+    #[error(transparent)]
+    Address(#[from] keys::address::Error),
+    #[error(transparent)]
+    JsonRpc(#[from] jsonrpsee_core::Error),
+    #[error("fee stats response is missing {0}")]
+    MissingFeeStat(String),
+    #[error("inner transaction must be a signed v1 transaction envelope")]
+    InnerTransaction,
+    #[error("fee of {fee} stroops is lower than the {minimum} stroops the network requires")]
+    FeeTooLow { fee: i64, minimum: i64 },
+}
+
+#[derive(ValueEnum, Debug, Clone, Copy, Default)]
+pub enum Percentile {
+    P10,
+    P50,
+    #[default]
+    P90,
+    P99,
+    Max,
+}
+
+impl Percentile {
+    fn key(self) -> &'static str {
+        match self {
+            Percentile::P10 => "p10",
+            Percentile::P50 => "p50",
+            Percentile::P90 => "p90",
+            Percentile::P99 => "p99",
+            Percentile::Max => "max",
+        }
+    }
+}
+
+/// Wrap a signed transaction envelope from stdin in a fee bump transaction
+/// e.g. `cat signed.txt | stellar tx bump-fee --fee-source alice`
+///
+/// The fee is derived from the network's recent inclusion fees unless `--fee`
+/// is given. The output is unsigned and must be signed by the fee source.
+#[derive(Debug, clap::Parser, Clone)]
+#[group(skip)]
+pub struct Cmd {
+    /// Account that pays the fee, either an identity or a public key (G...)
+    #[arg(long)]
+    pub fee_source: String,
+    /// Total fee in stroops, instead of deriving it from the network's fee stats
+    #[arg(long)]
+    pub fee: Option<i64>,
+    /// Percentile of recent inclusion fees to bid
+    #[arg(long, value_enum, default_value_t)]
+    pub percentile: Percentile,
+    #[command(flatten)]
+    pub network: network::Args,
+    #[command(flatten)]
+    pub locator: locator::Args,
+}
+
+impl Cmd {
+    pub async fn run(&self) -> Result<(), Error> {
+        let inner = super::xdr::tx_envelope_from_stdin()?;
+        let envelope = self.bump_fee(inner).await?;
+        println!("{}", envelope.to_xdr_base64(Limits::none())?);
+        Ok(())
+    }
+
+    pub async fn bump_fee(&self, inner: TransactionEnvelope) -> Result<TransactionEnvelope, Error> {
+        let TransactionEnvelope::Tx(inner) = inner else {
+            return Err(Error::InnerTransaction);
+        };
+        if inner.signatures.is_empty() {
+            return Err(Error::InnerTransaction);
+        }
+        let fee_source = keys::address::Cmd {
+            name: self.fee_source.clone(),
+            hd_path: None,
+            locator: self.locator.clone(),
+        }
+        .public_key()?;
+
+        let minimum = minimum_fee(&inner, 100);
+        let fee = if let Some(fee) = self.fee {
+            fee
+        } else {
+            let inclusion_fee = self.inclusion_fee(is_soroban(&inner)).await?;
+            minimum_fee(&inner, inclusion_fee).max(i64::from(inner.tx.fee) + inclusion_fee)
+        };
+        if fee < minimum {
+            return Err(Error::FeeTooLow { fee, minimum });
+        }
+
+        Ok(TransactionEnvelope::TxFeeBump(FeeBumpTransactionEnvelope {
+            tx: FeeBumpTransaction {
+                fee_source: MuxedAccount::Ed25519(Uint256(fee_source.0)),
+                fee,
+                inner_tx: FeeBumpTransactionInnerTx::Tx(inner),
+                ext: FeeBumpTransactionExt::V0,
+            },
+            signatures: xdr::VecM::default(),
+        }))
+    }
+
+    /// Per operation inclusion fee at the requested percentile of the network's fee stats.
+    async fn inclusion_fee(&self, soroban: bool) -> Result<i64, Error> {
+        let network = self.network.get(&self.locator)?;
+        let url = network.rpc_urls().next().unwrap_or_default();
+        let client = HttpClientBuilder::default().build(url)?;
+        let stats: Value = client.request("getFeeStats", rpc_params![]).await?;
+        let kind = if soroban {
+            "sorobanInclusionFee"
+        } else {
+            "inclusionFee"
+        };
+        let key = self.percentile.key();
+        stats[kind][key]
+            .as_str()
+            .and_then(|fee| fee.parse().ok())
+            .ok_or_else(|| Error::MissingFeeStat(format!("{kind}.{key}")))
+    }
+}
+
+fn is_soroban(inner: &TransactionV1Envelope) -> bool {
+    matches!(inner.tx.ext, TransactionExt::V1(_))
+}
+
+/// The lowest fee a fee bump may bid at `inclusion_fee` per operation: one fee for each inner
+/// operation plus one for the fee bump itself, plus the inner transaction's resource fee.
+fn minimum_fee(inner: &TransactionV1Envelope, inclusion_fee: i64) -> i64 {
+    let resource_fee = match &inner.tx.ext {
+        TransactionExt::V1(SorobanTransactionData { resource_fee, .. }) => *resource_fee,
+        TransactionExt::V0 => 0,
+    };
+    let fees = i64::try_from(inner.tx.operations.len() + 1).unwrap_or(i64::MAX);
+    inclusion_fee.saturating_mul(fees) + resource_fee
+}
@@ -2,6 +2,7 @@ use clap::Parser;
 
 use super::global;
 
+pub mod bump_fee;
 pub mod simulate;
 pub mod xdr;
 
@@ -9,6 +10,8 @@ pub mod xdr;
 pub enum Cmd {
     /// Simulate a transaction envelope from stdin
     Simulate(simulate::Cmd),
+    /// Wrap a signed transaction envelope from stdin in a fee bump transaction
+    BumpFee(bump_fee::Cmd),
 }
 
 #[derive(thiserror::Error, Debug)]
@@ -16,12 +19,15 @@ pub enum Error {
     /// An error during the simulation
     #[error(transparent)]
     Simulate(#[from] simulate::Error),
+    #[error(transparent)]
+    BumpFee(#[from] bump_fee::Error),
 }
 
 impl Cmd {
     pub async fn run(&self, global_args: &global::Args) -> Result<(), Error> {
         match self {
             Cmd::Simulate(cmd) => cmd.run(global_args).await?,
+            Cmd::BumpFee(cmd) => cmd.run().await?,
         };
         Ok(())
     }
@@ -2,7 +2,8 @@ use crate::xdr::{self, TransactionEnvelope, WriteXdr};
 use async_trait::async_trait;
 use soroban_rpc::Assembled;
 
-use crate::commands::{config, global, NetworkRunnable};
+use crate::commands::{config, global, network, NetworkRunnable};
+use crate::print::Print;
 
 #[derive(thiserror::Error, Debug)]
 pub enum Error {
@@ -13,6 +14,8 @@ pub enum Error {
     #[error(transparent)]
     Rpc(#[from] crate::rpc::Error),
     #[error(transparent)]
+    Network(#[from] network::Error),
+    #[error(transparent)]
     Xdr(#[from] xdr::Error),
 }
 
@@ -43,13 +46,16 @@ impl NetworkRunnable for Cmd {
     type Result = Assembled;
     async fn run_against_rpc_server(
         &self,
-        _: Option<&global::Args>,
+        global_args: Option<&global::Args>,
         config: Option<&config::Args>,
     ) -> Result<Self::Result, Self::Error> {
+        let print = global_args.map(Print::new).unwrap_or_default();
         let config = config.unwrap_or(&self.config);
         let network = config.get_network()?;
-        let client = crate::rpc::Client::new(&network.rpc_url)?;
+        let client = network.rpc_client(&print).await?;
         let tx = super::xdr::unwrap_envelope_v1(super::xdr::tx_envelope_from_stdin()?)?;
-        Ok(client.simulate_and_assemble_transaction(&tx).await?)
+        Ok(client
+            .retry(|| client.simulate_and_assemble_transaction(&tx))
+            .await?)
     }
 }
@@ -0,0 +1,97 @@
+use std::{
+    fs, io,
+    path::{Path, PathBuf},
+};
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error("reading env file {0:?}: {1}")]
+    Read(PathBuf, io::Error),
+    #[error("writing env file {0:?}: {1}")]
+    Write(PathBuf, io::Error),
+}
+
+/// Set variables in a shell-sourceable env file, replacing earlier values of the same variables
+/// and keeping the file's other lines.
+pub fn export(path: &Path, vars: &[(String, String)]) -> Result<(), Error> {
+    let contents = match fs::read_to_string(path) {
+        Ok(contents) => contents,
+        Err(e) if e.kind() == io::ErrorKind::NotFound => String::new(),
+        Err(e) => return Err(Error::Read(path.to_path_buf(), e)),
+    };
+    fs::write(path, merge(&contents, vars)).map_err(|e| Error::Write(path.to_path_buf(), e))
+}
+
+/// Name of a variable holding a value for `name`, e.g. `CONTRACT_ID_HELLO_WORLD` for the prefix
+/// `CONTRACT_ID` and the name `hello-world`.
+pub fn var_name(prefix: &str, name: &str) -> String {
+    let name = name
+        .chars()
+        .map(|c| {
+            if c.is_ascii_alphanumeric() {
+                c.to_ascii_uppercase()
+            } else {
+                '_'
+            }
+        })
+        .collect::<String>();
+    format!("{prefix}_{name}")
+}
+
+fn merge(contents: &str, vars: &[(String, String)]) -> String {
+    let mut lines = contents.lines().map(String::from).collect::<Vec<_>>();
+    for (name, value) in vars {
+        let line = format!("{name}={}", quote(value));
+        let existing = lines.iter_mut().find(|line| {
+            let line = line.trim_start();
+            let line = line.strip_prefix("export ").unwrap_or(line);
+            line.split_once('=').map(|(key, _)| key.trim()) == Some(name.as_str())
+        });
+        match existing {
+            Some(existing) if existing.trim_start().starts_with("export ") => {
+                *existing = format!("export {line}");
+            }
+            Some(existing) => *existing = line,
+            None => lines.push(line),
+        }
+    }
+    let mut merged = lines.join("\n");
+    merged.push('\n');
+    merged
+}
+
+/// Single quote the value, so that shells and dotenv parsers read it as is.
+fn quote(value: &str) -> String {
+    format!("'{}'", value.replace('\'', r"'\''"))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn replaces_and_appends_vars() {
+        let contents = "# deployment\nexport RPC_URL='http://old'\nOTHER=1\n";
+        let vars = [
+            (
+                "RPC_URL".to_string(),
+                "http://localhost:8000/soroban/rpc".to_string(),
+            ),
+            ("OTHER".to_string(), "2".to_string()),
+            (var_name("CONTRACT_ID", "hello-world"), "CABC".to_string()),
+        ];
+        assert_eq!(
+            merge(contents, &vars),
+            "# deployment\nexport RPC_URL='http://localhost:8000/soroban/rpc'\nOTHER='2'\nCONTRACT_ID_HELLO_WORLD='CABC'\n"
+        );
+    }
+
+    #[test]
+    fn quotes_values() {
+        assert_eq!(
+            quote("Test SDF Network ; September 2015"),
+            "'Test SDF Network ; September 2015'"
+        );
+        assert_eq!(quote("it's"), r"'it'\''s'");
+    }
+}
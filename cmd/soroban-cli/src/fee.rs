@@ -23,6 +23,9 @@ pub struct Args {
     /// Simulate the transaction and only write the base64 xdr to stdout
     #[arg(long, help_heading = HEADING_RPC, conflicts_with = "build_only")]
     pub sim_only: bool,
+    /// Submit without reviewing the transaction. Needed to submit when stdin is not a terminal
+    #[arg(long, short = 'y', env = "STELLAR_YES", help_heading = HEADING_RPC)]
+    pub yes: bool,
 }
 
 impl Args {
@@ -56,6 +59,7 @@ impl Default for Args {
             instructions: None,
             build_only: false,
             sim_only: false,
+            yes: false,
         }
     }
 }
@@ -10,6 +10,7 @@ pub use soroban_spec_tools::contract as contract_spec;
 use crate::commands::config::{self, locator};
 use crate::commands::network;
 use crate::commands::{config::data, global};
+use crate::print::Print;
 use crate::rpc;
 
 #[derive(thiserror::Error, Debug)]
@@ -46,9 +47,12 @@ pub async fn get_remote_contract_spec(
         |c| c.get_network().map_err(Error::from),
     )?;
     tracing::trace!(?network);
-    let client = rpc::Client::new(&network.rpc_url)?;
+    let print = global_args.map(Print::new).unwrap_or_default();
+    let client = network.rpc_client(&print).await?;
     // Get contract data
-    let r = client.get_contract_data(contract_id).await?;
+    let r = client
+        .retry(|| client.get_contract_data(contract_id))
+        .await?;
     tracing::trace!("{r:?}");
 
     let ContractDataEntry {
@@ -66,7 +70,9 @@ pub async fn get_remote_contract_spec(
             if let Ok(entries) = data::read_spec(&hash_str) {
                 entries
             } else {
-                let raw_wasm = client.get_remote_wasm_from_hash(hash).await?;
+                let raw_wasm = client
+                    .retry(|| client.get_remote_wasm_from_hash(hash.clone()))
+                    .await?;
                 let res = contract_spec::Spec::new(&raw_wasm)?;
                 let res = res.spec;
                 if global_args.map_or(true, |a| !a.no_cache) {
@@ -12,10 +12,13 @@ mod cli;
 pub use cli::main;
 
 pub mod commands;
+pub mod env_file;
 pub mod fee;
 pub mod get_spec;
 pub mod key;
 pub mod log;
+pub mod print;
+pub mod review;
 pub mod signer;
 pub mod toid;
 pub mod utils;
@@ -0,0 +1,122 @@
+use std::{
+    fmt::Display,
+    io::{IsTerminal, Write},
+};
+
+use serde_json::json;
+use termcolor::{Color, ColorChoice, ColorSpec, StandardStream, WriteColor};
+
+use crate::commands::global;
+
+/// Status messages written by commands, e.g. progress, warnings and errors.
+///
+/// Everything written through `Print` goes to stderr so that stdout only ever
+/// contains the result of a command, which is still printed with `println!`.
+/// Status messages are suppressed entirely by `--quiet`.
+/// Emoji and colors are dropped with `--no-color`, the `NO_COLOR` env var, or
+/// when stderr is not a terminal.
+///
+/// With `--progress json` every message is written as a JSON object on its own
+/// line instead, and long operations also report their progress, e.g.
+/// `{"type":"progress","operation":"deploy","current":1,"total":3,"message":"Uploading wasm"}`.
+#[derive(Debug, Clone, Copy, Default)]
+pub struct Print {
+    pub quiet: bool,
+    pub color: bool,
+    pub progress: global::Progress,
+}
+
+impl Print {
+    pub fn new(global_args: &global::Args) -> Print {
+        Print {
+            quiet: global_args.quiet,
+            color: !global_args.no_color
+                && std::env::var_os("NO_COLOR").is_none()
+                && std::io::stderr().is_terminal(),
+            progress: global_args.progress,
+        }
+    }
+
+    pub fn is_json(&self) -> bool {
+        self.progress == global::Progress::Json
+    }
+
+    pub fn print<T: Display>(&self, message: T) {
+        if self.is_json() {
+            self.json("message", message);
+        } else if !self.quiet {
+            eprint!("{message}");
+        }
+    }
+
+    pub fn println<T: Display>(&self, message: T) {
+        if self.is_json() {
+            self.json("message", message);
+        } else if !self.quiet {
+            eprintln!("{message}");
+        }
+    }
+
+    /// Report that step `current` of `total` of a long operation has started. Only written with
+    /// `--progress json`, the other messages of the operation already tell people where it is.
+    pub fn progressln<T: Display>(&self, operation: &str, current: u64, total: u64, message: T) {
+        if self.quiet || !self.is_json() {
+            return;
+        }
+        eprintln!(
+            "{}",
+            json!({
+                "type": "progress",
+                "operation": operation,
+                "current": current,
+                "total": total,
+                "message": message.to_string(),
+            })
+        );
+    }
+
+    fn json<T: Display>(&self, kind: &str, message: T) {
+        if !self.quiet {
+            eprintln!(
+                "{}",
+                json!({ "type": kind, "message": message.to_string() })
+            );
+        }
+    }
+
+    fn line<T: Display>(&self, kind: &str, icon: &str, color: Option<Color>, message: T) {
+        if self.quiet {
+            return;
+        }
+        if self.is_json() {
+            self.json(kind, message);
+            return;
+        }
+        if !self.color {
+            eprintln!("{message}");
+            return;
+        }
+        let mut stderr = StandardStream::stderr(ColorChoice::Auto);
+        let _ = stderr.set_color(ColorSpec::new().set_fg(color));
+        let _ = writeln!(stderr, "{icon}{message}");
+        let _ = stderr.reset();
+    }
+}
+
+macro_rules! create_print_functions {
+    ($name:ident, $kind:expr, $icon:expr, $color:expr) => {
+        impl Print {
+            pub fn $name<T: Display>(&self, message: T) {
+                self.line($kind, $icon, $color, message);
+            }
+        }
+    };
+}
+
+create_print_functions!(infoln, "info", "ℹ️  ", None);
+create_print_functions!(checkln, "check", "✅ ", Some(Color::Green));
+create_print_functions!(warnln, "warn", "⚠️  ", Some(Color::Yellow));
+create_print_functions!(errorln, "error", "⛔ ", Some(Color::Red));
+create_print_functions!(plusln, "plus", "➕  ", None);
+create_print_functions!(globeln, "globe", "🌎 ", None);
+create_print_functions!(saveln, "save", "💾 ", None);
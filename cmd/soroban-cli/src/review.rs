@@ -0,0 +1,234 @@
+use std::{
+    collections::HashMap,
+    io::{self, IsTerminal, Write},
+};
+
+use crate::commands::{address, config::locator};
+use crate::print::Print;
+use crate::xdr::{
+    AccountId, ContractExecutable, ContractIdPreimage, CreateContractArgs, HostFunction,
+    InvokeContractArgs, InvokeHostFunctionOp, MuxedAccount, OperationBody, PublicKey, ScAddress,
+    SorobanCredentials, SorobanTransactionData, Transaction, TransactionExt, Uint256,
+};
+
+#[derive(thiserror::Error, Debug)]
+pub enum Error {
+    #[error("reading confirmation: {0}")]
+    Confirmation(io::Error),
+    #[error("transaction was not submitted")]
+    Declined,
+    #[error("cannot ask {0:?} without a terminal, pass --yes to go ahead")]
+    NoTerminal(String),
+    #[error("cannot ask {0:?} with --quiet, pass --yes to go ahead")]
+    Quiet(String),
+}
+
+/// Show a summary of the transaction, with the labels of the addresses in `locator`'s address
+/// book, and ask to submit it, unless `yes` is set. Without a terminal to ask on it fails, so
+/// scripts have to pass `--yes` to submit.
+pub fn review(
+    tx: &Transaction,
+    network_passphrase: &str,
+    locator: &locator::Args,
+    yes: bool,
+    print: &Print,
+) -> Result<(), Error> {
+    if yes {
+        return Ok(());
+    }
+    if !io::stdin().is_terminal() {
+        return Err(Error::NoTerminal("Submit transaction?".to_string()));
+    }
+    let labels = locator.address_labels().unwrap_or_default();
+    for line in summary(tx, network_passphrase, &labels) {
+        print.println(line);
+    }
+    if confirm("Submit transaction?", print)? {
+        Ok(())
+    } else {
+        Err(Error::Declined)
+    }
+}
+
+/// Ask a yes or no question on stderr, defaulting to no. Without a terminal to answer on it fails
+/// rather than reading the answer from piped input, and with `--quiet` rather than asking silently.
+pub fn confirm(question: &str, print: &Print) -> Result<bool, Error> {
+    if !io::stdin().is_terminal() {
+        return Err(Error::NoTerminal(question.to_string()));
+    }
+    if print.quiet {
+        return Err(Error::Quiet(question.to_string()));
+    }
+    print.print(format!("{question} [y/N] "));
+    io::stderr().flush().map_err(Error::Confirmation)?;
+    let mut answer = String::new();
+    io::stdin()
+        .read_line(&mut answer)
+        .map_err(Error::Confirmation)?;
+    Ok(matches!(answer.trim().to_lowercase().as_str(), "y" | "yes"))
+}
+
+/// Human readable description of what submitting the transaction will do, with `labels` written
+/// next to the addresses they belong to.
+pub fn summary(
+    tx: &Transaction,
+    network_passphrase: &str,
+    labels: &HashMap<String, String>,
+) -> Vec<String> {
+    let source = muxed_account(&tx.source_account);
+    let mut lines = vec![
+        format!("Network: {network_passphrase}"),
+        format!("Source account: {source}"),
+    ];
+    let mut signers = vec![source];
+    for op in tx.operations.iter() {
+        lines.push(format!("Operation: {}", op.body.name()));
+        if let Some(account) = &op.source_account {
+            let account = muxed_account(account);
+            lines.push(format!("  Source account: {account}"));
+            if !signers.contains(&account) {
+                signers.push(account);
+            }
+        }
+        let OperationBody::InvokeHostFunction(InvokeHostFunctionOp {
+            host_function,
+            auth,
+        }) = &op.body
+        else {
+            continue;
+        };
+        match host_function {
+            HostFunction::InvokeContract(InvokeContractArgs {
+                contract_address,
+                function_name,
+                args,
+            }) => {
+                lines.push(format!("  Contract: {}", address(contract_address)));
+                lines.push(format!(
+                    "  Function: {}",
+                    function_name.to_utf8_string_lossy()
+                ));
+                for (i, arg) in args.iter().enumerate() {
+                    let arg =
+                        soroban_spec_tools::to_string(arg).unwrap_or_else(|_| format!("{arg:?}"));
+                    lines.push(format!("  Argument {}: {arg}", i + 1));
+                }
+            }
+            HostFunction::CreateContract(CreateContractArgs {
+                contract_id_preimage,
+                executable,
+            }) => {
+                match contract_id_preimage {
+                    ContractIdPreimage::Address(preimage) => {
+                        lines.push(format!("  Deployer: {}", address(&preimage.address)));
+                    }
+                    ContractIdPreimage::Asset(asset) => {
+                        lines.push(format!("  Stellar asset: {asset:?}"));
+                    }
+                }
+                if let ContractExecutable::Wasm(hash) = executable {
+                    lines.push(format!("  Wasm hash: {}", hex::encode(hash.0)));
+                }
+            }
+            HostFunction::UploadContractWasm(wasm) => {
+                lines.push(format!("  Wasm size: {} bytes", wasm.len()));
+            }
+        }
+        for entry in auth.iter() {
+            if let SorobanCredentials::Address(credentials) = &entry.credentials {
+                let signer = address(&credentials.address);
+                if !signers.contains(&signer) {
+                    signers.push(signer);
+                }
+            }
+        }
+    }
+    lines.push(format!("Signers required: {}", signers.join(", ")));
+    let resource_fee = match &tx.ext {
+        TransactionExt::V1(SorobanTransactionData {
+            resources,
+            resource_fee,
+            ..
+        }) => {
+            lines.push(format!(
+                "Footprint: {} read-only and {} read-write entries",
+                resources.footprint.read_only.len(),
+                resources.footprint.read_write.len()
+            ));
+            *resource_fee
+        }
+        TransactionExt::V0 => 0,
+    };
+    lines.push(format!(
+        "Fee: {} stroops, of which {resource_fee} are resource fees",
+        tx.fee
+    ));
+    lines
+        .iter()
+        .map(|line| address::with_labels(line, labels))
+        .collect()
+}
+
+fn muxed_account(account: &MuxedAccount) -> String {
+    match account {
+        MuxedAccount::Ed25519(Uint256(key)) => stellar_strkey::ed25519::PublicKey(*key).to_string(),
+        MuxedAccount::MuxedEd25519(muxed) => stellar_strkey::ed25519::MuxedAccount {
+            ed25519: muxed.ed25519.0,
+            id: muxed.id,
+        }
+        .to_string(),
+    }
+}
+
+fn address(address: &ScAddress) -> String {
+    match address {
+        ScAddress::Account(AccountId(PublicKey::PublicKeyTypeEd25519(Uint256(key)))) => {
+            stellar_strkey::ed25519::PublicKey(*key).to_string()
+        }
+        ScAddress::Contract(hash) => stellar_strkey::Contract(hash.0).to_string(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::xdr::{Hash, Memo, Operation, Preconditions, ScVal, SequenceNumber};
+
+    #[test]
+    fn summarizes_invocation() {
+        let tx = Transaction {
+            source_account: MuxedAccount::Ed25519(Uint256([0; 32])),
+            fee: 100,
+            seq_num: SequenceNumber(1),
+            cond: Preconditions::None,
+            memo: Memo::None,
+            operations: vec![Operation {
+                source_account: None,
+                body: OperationBody::InvokeHostFunction(InvokeHostFunctionOp {
+                    host_function: HostFunction::InvokeContract(InvokeContractArgs {
+                        contract_address: ScAddress::Contract(Hash([0; 32])),
+                        function_name: "hello".try_into().unwrap(),
+                        args: vec![ScVal::U32(7)].try_into().unwrap(),
+                    }),
+                    auth: vec![].try_into().unwrap(),
+                }),
+            }]
+            .try_into()
+            .unwrap(),
+            ext: TransactionExt::V0,
+        };
+        let labels = HashMap::from([(
+            "CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABSC4".to_string(),
+            "hello".to_string(),
+        )]);
+        let lines = summary(&tx, "Test SDF Network ; September 2015", &labels);
+        assert!(lines.contains(&"Operation: InvokeHostFunction".to_string()));
+        assert!(lines.contains(
+            &"  Contract: CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABSC4 (hello)"
+                .to_string()
+        ));
+        assert!(lines.contains(&"  Function: hello".to_string()));
+        assert!(lines.contains(&"  Argument 1: 7".to_string()));
+        assert!(lines.contains(&"Fee: 100 stroops, of which 0 are resource fees".to_string()));
+    }
+}
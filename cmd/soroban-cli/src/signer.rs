@@ -1,4 +1,11 @@
-use ed25519_dalek::ed25519::signature::Signer;
+use std::{
+    io::{self, Write},
+    process::{Command, ExitStatus, Stdio},
+};
+
+use base64::{engine::general_purpose::STANDARD, Engine as _};
+use ed25519_dalek::{ed25519::signature::Signer, VerifyingKey};
+use serde::{Deserialize, Serialize};
 use sha2::{Digest, Sha256};
 
 use soroban_env_host::xdr::{
@@ -7,7 +14,7 @@ use soroban_env_host::xdr::{
     ScVal, Signature, SignatureHint, SorobanAddressCredentials, SorobanAuthorizationEntry,
     SorobanAuthorizedFunction, SorobanCredentials, Transaction, TransactionEnvelope,
     TransactionSignaturePayload, TransactionSignaturePayloadTaggedTransaction,
-    TransactionV1Envelope, Uint256, WriteXdr,
+    TransactionV1Envelope, Uint256, VecM, WriteXdr,
 };
 
 #[derive(thiserror::Error, Debug)]
@@ -24,6 +31,19 @@ pub enum Error {
     UserCancelledSigning,
     #[error(transparent)]
     Xdr(#[from] xdr::Error),
+    #[error("running signing command `{0}`: {1}")]
+    SignCommand(String, io::Error),
+    #[error("signing command `{0}` failed with {1}")]
+    SignCommandFailed(String, ExitStatus),
+    #[error("signing command `{0}` did not print a valid signature for {1}")]
+    InvalidCommandSignature(String, String),
+}
+
+/// External program declared in the config to sign transactions with, e.g. a wrapper around a KMS
+/// or HSM.
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq, Eq)]
+pub struct Plugin {
+    pub command: String,
 }
 
 fn requires_auth(txn: &Transaction) -> Option<xdr::Operation> {
@@ -45,7 +65,7 @@ fn requires_auth(txn: &Transaction) -> Option<xdr::Operation> {
 // transaction. If unable to sign, return an error.
 pub fn sign_soroban_authorizations(
     raw: &Transaction,
-    source_key: &ed25519_dalek::SigningKey,
+    source_key: Option<&ed25519_dalek::SigningKey>,
     signers: &[ed25519_dalek::SigningKey],
     signature_expiration_ledger: u32,
     network_passphrase: &str,
@@ -65,9 +85,6 @@ pub fn sign_soroban_authorizations(
 
     let network_id = Hash(Sha256::digest(network_passphrase.as_bytes()).into());
 
-    let verification_key = source_key.verifying_key();
-    let source_address = verification_key.as_bytes();
-
     let signed_auths = body
         .auth
         .as_slice()
@@ -102,7 +119,9 @@ pub fn sign_soroban_authorizations(
                 .find(|s| needle == s.verifying_key().as_bytes())
             {
                 s
-            } else if needle == source_address {
+            } else if let Some(source_key) =
+                source_key.filter(|key| needle == key.verifying_key().as_bytes())
+            {
                 // This is the source address, so we can sign it
                 source_key
             } else {
@@ -208,6 +227,78 @@ pub fn sign_tx(
     }))
 }
 
+/// Sign the transaction with an external program run through the shell. The program gets the hex
+/// transaction hash on stdin, along with the unsigned envelope and the signer's public key in
+/// its environment, and must print the hex or base64 ed25519 signature of the hash.
+pub fn sign_tx_with_cmd(
+    command: &str,
+    key: &VerifyingKey,
+    tx: &Transaction,
+    network_passphrase: &str,
+) -> Result<TransactionEnvelope, Error> {
+    let tx_hash = hash(tx, network_passphrase)?;
+    let unsigned = TransactionEnvelope::Tx(TransactionV1Envelope {
+        tx: tx.clone(),
+        signatures: VecM::default(),
+    });
+    let public_key = stellar_strkey::ed25519::PublicKey(key.to_bytes()).to_string();
+    let mut child = shell(command)
+        .env("STELLAR_TX_HASH", hex::encode(tx_hash))
+        .env(
+            "STELLAR_TX_ENVELOPE",
+            unsigned.to_xdr_base64(Limits::none())?,
+        )
+        .env("STELLAR_NETWORK_PASSPHRASE", network_passphrase)
+        .env("STELLAR_SIGNER_PUBLIC_KEY", &public_key)
+        .stdin(Stdio::piped())
+        .stdout(Stdio::piped())
+        .spawn()
+        .map_err(|e| Error::SignCommand(command.to_string(), e))?;
+    if let Some(mut stdin) = child.stdin.take() {
+        // Programs that only read their environment may exit without reading stdin
+        let _ = writeln!(stdin, "{}", hex::encode(tx_hash));
+    }
+    let output = child
+        .wait_with_output()
+        .map_err(|e| Error::SignCommand(command.to_string(), e))?;
+    if !output.status.success() {
+        return Err(Error::SignCommandFailed(command.to_string(), output.status));
+    }
+    let invalid = || Error::InvalidCommandSignature(command.to_string(), public_key.clone());
+    let signature =
+        parse_signature(&String::from_utf8_lossy(&output.stdout)).ok_or_else(invalid)?;
+    key.verify_strict(&tx_hash, &signature)
+        .map_err(|_| invalid())?;
+
+    let decorated_signature = DecoratedSignature {
+        hint: SignatureHint(key.to_bytes()[28..].try_into()?),
+        signature: Signature(signature.to_bytes().try_into()?),
+    };
+    Ok(TransactionEnvelope::Tx(TransactionV1Envelope {
+        tx: tx.clone(),
+        signatures: [decorated_signature].try_into()?,
+    }))
+}
+
+fn shell(command: &str) -> Command {
+    let mut cmd = if cfg!(windows) {
+        Command::new("cmd")
+    } else {
+        Command::new("sh")
+    };
+    cmd.arg(if cfg!(windows) { "/C" } else { "-c" })
+        .arg(command);
+    cmd
+}
+
+fn parse_signature(output: &str) -> Option<ed25519_dalek::Signature> {
+    let output = output.trim();
+    let bytes = hex::decode(output)
+        .ok()
+        .or_else(|| STANDARD.decode(output).ok())?;
+    ed25519_dalek::Signature::from_slice(&bytes).ok()
+}
+
 pub fn hash(tx: &Transaction, network_passphrase: &str) -> Result<[u8; 32], xdr::Error> {
     let signature_payload = TransactionSignaturePayload {
         network_id: Hash(Sha256::digest(network_passphrase).into()),
@@ -215,3 +306,18 @@ pub fn hash(tx: &Transaction, network_passphrase: &str) -> Result<[u8; 32], xdr:
     };
     Ok(Sha256::digest(signature_payload.to_xdr(Limits::none())?).into())
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn signature_in_hex_or_base64() {
+        let bytes = [7; 64];
+        let hex = format!("{}\n", hex::encode(bytes));
+        let base64 = STANDARD.encode(bytes);
+        assert_eq!(parse_signature(&hex).unwrap().to_bytes(), bytes);
+        assert_eq!(parse_signature(&base64).unwrap().to_bytes(), bytes);
+        assert!(parse_signature("not a signature").is_none());
+    }
+}
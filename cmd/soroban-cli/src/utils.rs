@@ -116,6 +116,21 @@ pub fn is_hex_string(s: &str) -> bool {
     s.chars().all(|s| s.is_ascii_hexdigit())
 }
 
+/// Render `data` as a QR code drawn with unicode block characters, light on dark so it scans
+/// from a terminal with a dark background.
+///
+/// # Errors
+///
+/// Might return an error if `data` is too long to fit in a QR code
+pub fn qr_code(data: &str) -> Result<String, qrcode::types::QrError> {
+    use qrcode::render::unicode::Dense1x2;
+    Ok(qrcode::QrCode::new(data)?
+        .render::<Dense1x2>()
+        .dark_color(Dense1x2::Light)
+        .light_color(Dense1x2::Dark)
+        .build())
+}
+
 pub fn contract_id_hash_from_asset(
     asset: &Asset,
     network_passphrase: &str,